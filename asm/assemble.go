@@ -0,0 +1,528 @@
+// Package asm provides a text assembler for vee-em bytecode.
+//
+// Every line contains an optional label, and an optional instruction or
+// directive. Comments start with a semicolon:
+//
+//	.magic "VM"             ; the magic header, before any instruction
+//	main:
+//	    LoadImmediate r0, 10
+//	    LoadImmediate r1, 'a'
+//	loop:
+//	    SUB r0, r0, r2      ; mnemonics are case-insensitive
+//	    JmpImmediateIfNotZero r0, loop
+//	    Halt
+//
+// Registers are written as r0 to r31. Immediates and addresses can be
+// decimal, hexadecimal (0x), octal (0o) or binary (0b) numbers,
+// character literals, or labels. Labels are absolute addresses in the
+// program, including the magic header, and may be used before they are
+// defined.
+//
+// Initialized heap values are declared with .data, which sets the heap
+// address, and .word, which stores values at consecutive heap addresses.
+// A string stores one value per character. Labels on these lines are heap
+// addresses:
+//
+//	.data 0x100
+//	table: .word 1, 2, 3, 'a', end
+//	hello: .word "Hello"
+package asm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// Program defines the output of the assembler.
+type Program struct {
+	// The bytecode of the program, starting with the magic header.
+	Bytecode []byte
+	// The magic header of the program,
+	// which should be passed to vm.WithMagicHeader.
+	MagicHeader []byte
+	// The labels of the program.
+	Symbols *vm.SymbolTable
+	// The source line of every instruction.
+	SourceMap *vm.SourceMap
+	// The initialized heap values,
+	// which should be passed to vm.WithHeapImage.
+	Data []vm.DataSegment
+}
+
+// assembler holds the state of a single assembly.
+type assembler struct {
+	// The name of the source file, which is used in the source map.
+	filename string
+	// The errors that have been found so far.
+	errs []error
+	// The addresses of the labels.
+	labels map[string]uint64
+	// The labels, in order of definition.
+	symbols []vm.Symbol
+	// The source line of every instruction.
+	mappings []vm.SourceMapping
+	// The magic header of the program.
+	magicHeader []byte
+	// The bytecode that has been assembled so far.
+	bytecode []byte
+	// The heap address of the next .word value.
+	dataAddr uint64
+	// The initialized heap values that have been assembled so far.
+	data []vm.DataSegment
+}
+
+// Assemble assembles source code into bytecode.
+// When the source contains errors, all of them are returned, joined,
+// and each of them is an *Error with the line and column.
+func Assemble(src string) ([]byte, error) {
+	program, err := AssembleFile("", src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return program.Bytecode, nil
+}
+
+// AssembleFile assembles source code into a program, along with its symbols
+// and source map. The filename is used in the source map.
+func AssembleFile(filename string, src string) (*Program, error) {
+	a := &assembler{
+		filename:    filename,
+		errs:        nil,
+		labels:      map[string]uint64{},
+		symbols:     nil,
+		mappings:    nil,
+		magicHeader: []byte{},
+		bytecode:    []byte{},
+		dataAddr:    0,
+		data:        nil,
+	}
+
+	statements, errs := parse(src)
+	a.errs = append(a.errs, errs...)
+
+	a.defineLabels(statements)
+
+	for _, stmt := range statements {
+		a.assembleStatement(stmt)
+	}
+
+	if len(a.errs) != 0 {
+		return nil, errors.Join(a.errs...)
+	}
+
+	return &Program{
+		Bytecode:    a.bytecode,
+		MagicHeader: a.magicHeader,
+		Symbols:     vm.NewSymbolTable(a.symbols),
+		SourceMap:   vm.NewSourceMap(a.mappings),
+		Data:        a.data,
+	}, nil
+}
+
+func (a *assembler) fail(err error, tok token, lineNum int, detail string) {
+	a.errs = append(a.errs, &Error{Err: err, Detail: detail, Line: lineNum, Column: tok.col})
+}
+
+// defineLabels determines the address of every label,
+// so labels can be used before they are defined.
+// Labels on .data and .word lines, or on the lines before them,
+// get a heap address, and are not added to the symbols.
+func (a *assembler) defineLabels(statements []statement) {
+	var addr uint64
+	var dataAddr uint64
+	var pending []statement
+
+	for _, stmt := range statements {
+		if stmt.name.text == ".data" {
+			dataAddr, _ = a.dataAddress(stmt, false)
+		}
+
+		pending = append(pending, stmt)
+
+		if stmt.name.text == "" {
+			continue
+		}
+
+		isData := stmt.name.text == ".data" || stmt.name.text == ".word"
+
+		for _, labelStmt := range pending {
+			for _, label := range labelStmt.labels {
+				if isData {
+					a.defineLabel(label, labelStmt.line, dataAddr, false)
+				} else {
+					a.defineLabel(label, labelStmt.line, addr, true)
+				}
+			}
+		}
+
+		pending = nil
+
+		if stmt.name.text == ".word" {
+			dataAddr += wordCount(stmt)
+		}
+
+		addr += a.statementSize(stmt)
+	}
+
+	for _, labelStmt := range pending {
+		for _, label := range labelStmt.labels {
+			a.defineLabel(label, labelStmt.line, addr, true)
+		}
+	}
+}
+
+func (a *assembler) defineLabel(label token, lineNum int, addr uint64, isCode bool) {
+	if !IsLabelName(label.text) {
+		a.fail(ErrSyntax, label, lineNum, fmt.Sprintf("invalid label name %q", label.text))
+
+		return
+	}
+
+	if _, isDefined := a.labels[label.text]; isDefined {
+		a.fail(ErrDuplicateLabel, label, lineNum, label.text)
+
+		return
+	}
+
+	a.labels[label.text] = addr
+
+	if isCode {
+		a.symbols = append(a.symbols, vm.Symbol{Name: label.text, Address: addr})
+	}
+}
+
+// statementSize returns the number of bytes that a statement assembles to.
+// Errors are reported when the statement is assembled.
+func (a *assembler) statementSize(stmt statement) uint64 {
+	switch {
+	case stmt.name.text == "":
+		return 0
+
+	case stmt.name.text == ".magic":
+		header, _ := a.directiveBytes(stmt, false)
+
+		return uint64(len(header))
+	}
+
+	opcode, isOpcode := vm.ParseOpcode(stmt.name.text)
+
+	if !isOpcode {
+		return 0
+	}
+
+	return vm.GetInstructionLen(opcode)
+}
+
+func (a *assembler) assembleStatement(stmt statement) {
+	switch {
+	case stmt.name.text == "":
+		return
+
+	case stmt.name.text == ".magic":
+		a.assembleMagic(stmt)
+
+		return
+
+	case stmt.name.text == ".data":
+		a.dataAddr, _ = a.dataAddress(stmt, true)
+
+		return
+
+	case stmt.name.text == ".word":
+		a.assembleWords(stmt)
+
+		return
+
+	case stmt.name.text[0] == '.':
+		a.fail(ErrUnknownDirective, stmt.name, stmt.line, stmt.name.text)
+
+		return
+	}
+
+	opcode, isOpcode := vm.ParseOpcode(stmt.name.text)
+
+	if !isOpcode {
+		a.fail(ErrUnknownMnemonic, stmt.name, stmt.line, stmt.name.text)
+
+		return
+	}
+
+	kinds := vm.GetOperandKinds(opcode)
+
+	if len(stmt.operands) != len(kinds) {
+		a.fail(
+			ErrOperandCount,
+			stmt.name,
+			stmt.line,
+			fmt.Sprintf("%s takes %d, got %d", opcode, len(kinds), len(stmt.operands)),
+		)
+
+		return
+	}
+
+	addr := uint64(len(a.bytecode))
+	instruction := []byte{byte(opcode)}
+
+	for i, kind := range kinds {
+		value, ok := a.operandValue(stmt.operands[i], stmt.line, kind)
+
+		if !ok {
+			return
+		}
+
+		if kind.Size() == 1 {
+			instruction = append(instruction, byte(value))
+		} else {
+			// #nosec: G115
+			instruction = binary.BigEndian.AppendUint64(instruction, uint64(value))
+		}
+	}
+
+	a.bytecode = append(a.bytecode, instruction...)
+	a.mappings = append(a.mappings, vm.SourceMapping{
+		Address: addr,
+		File:    a.filename,
+		Line:    uint64(stmt.line), // #nosec: G115
+	})
+}
+
+// operandValue returns the value of an operand.
+// When the operand is invalid, an error is reported, and false is returned.
+func (a *assembler) operandValue(tok token, lineNum int, kind vm.OperandKind) (int64, bool) {
+	switch kind {
+	case vm.OperandRegister:
+		value, isRegister := parseRegister(tok.text)
+
+		if tok.kind != tokenWord || !isRegister {
+			a.fail(ErrInvalidOperand, tok, lineNum, fmt.Sprintf("expected a register, got %s", tok.text))
+		}
+
+		return value, tok.kind == tokenWord && isRegister
+
+	case vm.OperandCount:
+		value, isNumber := parseNumber(tok.text)
+		isCount := tok.kind == tokenWord && isNumber && value >= 0 && value < vm.NumRegisters
+
+		if !isCount {
+			a.fail(
+				ErrInvalidOperand,
+				tok,
+				lineNum,
+				fmt.Sprintf("expected a count from 0 to %d, got %s", vm.NumRegisters-1, tok.text),
+			)
+		}
+
+		return value, isCount
+
+	case vm.OperandImmediate, vm.OperandAddress:
+		return a.immediateValue(tok, lineNum)
+	}
+
+	return 0, false
+}
+
+// immediateValue returns the value of a number, character or label.
+func (a *assembler) immediateValue(tok token, lineNum int) (int64, bool) {
+	if tok.kind == tokenChar {
+		value, isChar := parseChar(tok.text)
+
+		if !isChar {
+			a.fail(ErrInvalidOperand, tok, lineNum, fmt.Sprintf("invalid character %s", tok.text))
+		}
+
+		return value, isChar
+	}
+
+	if tok.kind != tokenWord {
+		a.fail(ErrInvalidOperand, tok, lineNum, fmt.Sprintf("expected a number or label, got %s", tok.text))
+
+		return 0, false
+	}
+
+	if value, isNumber := parseNumber(tok.text); isNumber {
+		return value, true
+	}
+
+	if _, isRegister := parseRegister(tok.text); isRegister {
+		a.fail(ErrInvalidOperand, tok, lineNum, fmt.Sprintf("expected a number or label, got %s", tok.text))
+
+		return 0, false
+	}
+
+	if !isLabel(tok.text) {
+		a.fail(ErrInvalidOperand, tok, lineNum, fmt.Sprintf("invalid number %s", tok.text))
+
+		return 0, false
+	}
+
+	addr, isDefined := a.labels[tok.text]
+
+	if !isDefined {
+		a.fail(ErrUnknownLabel, tok, lineNum, tok.text)
+
+		return 0, false
+	}
+
+	return int64(addr), true // #nosec: G115
+}
+
+func (a *assembler) assembleMagic(stmt statement) {
+	if len(a.bytecode) != len(a.magicHeader) || len(a.magicHeader) != 0 {
+		a.fail(
+			ErrSyntax,
+			stmt.name,
+			stmt.line,
+			".magic must come before any instruction, and only once",
+		)
+
+		return
+	}
+
+	header, ok := a.directiveBytes(stmt, true)
+
+	if !ok {
+		return
+	}
+
+	a.magicHeader = header
+	a.bytecode = append(a.bytecode, header...)
+}
+
+// directiveBytes returns the bytes of the operands of a directive,
+// which are strings, characters and numbers from 0 to 255.
+// When report is true, invalid operands are reported as errors.
+func (a *assembler) directiveBytes(stmt statement, report bool) ([]byte, bool) {
+	var data []byte
+
+	isValid := len(stmt.operands) != 0
+
+	if !isValid && report {
+		a.fail(ErrOperandCount, stmt.name, stmt.line, stmt.name.text+" takes at least 1")
+	}
+
+	for _, tok := range stmt.operands {
+		if tok.kind == tokenString {
+			s, err := strconv.Unquote(tok.text)
+
+			if err == nil {
+				data = append(data, s...)
+
+				continue
+			}
+		}
+
+		value, isNumber := parseNumber(tok.text)
+
+		if tok.kind == tokenChar {
+			value, isNumber = parseChar(tok.text)
+		}
+
+		if (tok.kind != tokenWord && tok.kind != tokenChar) || !isNumber || value < 0 || value > 0xFF {
+			isValid = false
+
+			if report {
+				a.fail(ErrInvalidOperand, tok, stmt.line, fmt.Sprintf("expected a string or byte, got %s", tok.text))
+			}
+
+			continue
+		}
+
+		data = append(data, byte(value))
+	}
+
+	return data, isValid
+}
+
+// dataAddress returns the heap address of a .data directive.
+// When report is true, an invalid address is reported as an error.
+func (a *assembler) dataAddress(stmt statement, report bool) (uint64, bool) {
+	if len(stmt.operands) != 1 {
+		if report {
+			a.fail(ErrOperandCount, stmt.name, stmt.line, fmt.Sprintf(".data takes 1, got %d", len(stmt.operands)))
+		}
+
+		return 0, false
+	}
+
+	tok := stmt.operands[0]
+	value, isNumber := parseNumber(tok.text)
+
+	if tok.kind != tokenWord || !isNumber || value < 0 {
+		if report {
+			a.fail(ErrInvalidOperand, tok, stmt.line, fmt.Sprintf("expected a heap address, got %s", tok.text))
+		}
+
+		return 0, false
+	}
+
+	return uint64(value), true
+}
+
+// wordCount returns the number of values that a .word directive stores.
+func wordCount(stmt statement) uint64 {
+	var count uint64
+
+	for _, tok := range stmt.operands {
+		s, err := strconv.Unquote(tok.text)
+
+		if tok.kind == tokenString && err == nil {
+			count += uint64(len([]rune(s)))
+		} else {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (a *assembler) assembleWords(stmt statement) {
+	if len(stmt.operands) == 0 {
+		a.fail(ErrOperandCount, stmt.name, stmt.line, ".word takes at least 1")
+
+		return
+	}
+
+	var values []int64
+
+	for _, tok := range stmt.operands {
+		if tok.kind == tokenString {
+			s, err := strconv.Unquote(tok.text)
+
+			if err != nil {
+				a.fail(ErrInvalidOperand, tok, stmt.line, fmt.Sprintf("invalid string %s", tok.text))
+
+				return
+			}
+
+			for _, c := range s {
+				values = append(values, int64(c))
+			}
+
+			continue
+		}
+
+		value, ok := a.immediateValue(tok, stmt.line)
+
+		if !ok {
+			return
+		}
+
+		values = append(values, value)
+	}
+
+	last := len(a.data) - 1
+
+	if last >= 0 && a.data[last].Address+uint64(len(a.data[last].Values)) == a.dataAddr {
+		a.data[last].Values = append(a.data[last].Values, values...)
+	} else {
+		a.data = append(a.data, vm.DataSegment{Address: a.dataAddr, Values: values})
+	}
+
+	a.dataAddr += uint64(len(values))
+}
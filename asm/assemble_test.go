@@ -0,0 +1,365 @@
+package asm
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+func TestAssemble(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		src      string
+		expected []byte
+	}{
+		{
+			name: "instructions",
+			src: `
+				LoadImmediate r0, 0x0102
+				add r2, r0, R31 ; comment
+				Push r2
+				HostCall 3, r1, 2
+				Halt
+			`,
+			expected: []byte{
+				byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0x01, 0x02,
+				byte(vm.OpcodeAdd), 2, 0, 31,
+				byte(vm.OpcodePush), 2,
+				byte(vm.OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 3, 1, 2,
+				byte(vm.OpcodeHalt),
+			},
+		},
+		{
+			name: "literals",
+			src: `
+				LoadImmediate r0, -1
+				LoadImmediate r1, 'a'
+				LoadImmediate r2, '\n'
+				LoadImmediate r3, 0b101
+				LoadImmediate r4, 0xFFFFFFFFFFFFFFFE
+			`,
+			expected: []byte{
+				byte(vm.OpcodeLoadImmediate), 0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				byte(vm.OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 'a',
+				byte(vm.OpcodeLoadImmediate), 2, 0, 0, 0, 0, 0, 0, 0, '\n',
+				byte(vm.OpcodeLoadImmediate), 3, 0, 0, 0, 0, 0, 0, 0, 5,
+				byte(vm.OpcodeLoadImmediate), 4, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE,
+			},
+		},
+		{
+			name: "labels and magic header",
+			src: `
+				.magic "V", 0x01
+				main: CallImmediate f
+				      Halt
+				f:
+				.end: JmpImmediate .end
+			`,
+			expected: []byte{
+				'V', 0x01,
+				byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 12,
+				byte(vm.OpcodeHalt),
+				byte(vm.OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0, 12,
+			},
+		},
+		{
+			name:     "empty",
+			src:      "; nothing here\n\n",
+			expected: []byte{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			bytecode, err := Assemble(test.src)
+
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if !bytes.Equal(bytecode, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, bytecode)
+			}
+		})
+	}
+}
+
+func TestAssembleFile(t *testing.T) {
+	t.Parallel()
+
+	program, err := AssembleFile("loop.s", `.magic "VM"
+		LoadImmediate r0, 3
+		LoadImmediate r1, 1
+	loop:
+		SUB r0, r0, r1
+		JmpImmediateIfNotZero r0, loop
+		HaltRegister r1
+	`)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !bytes.Equal(program.MagicHeader, []byte("VM")) {
+		t.Fatalf("expected the magic header to be \"VM\", got %q", program.MagicHeader)
+	}
+
+	if addr, _ := program.Symbols.Address("loop"); addr != 22 {
+		t.Fatalf("expected \"loop\" to be at address 22, got %d", addr)
+	}
+
+	expectedMappings := []vm.SourceMapping{
+		{Address: 2, File: "loop.s", Line: 2},
+		{Address: 12, File: "loop.s", Line: 3},
+		{Address: 22, File: "loop.s", Line: 5},
+		{Address: 26, File: "loop.s", Line: 6},
+		{Address: 36, File: "loop.s", Line: 7},
+	}
+
+	if !reflect.DeepEqual(program.SourceMap.Mappings(), expectedMappings) {
+		t.Fatalf("expected source map to be %v, got %v", expectedMappings, program.SourceMap.Mappings())
+	}
+
+	result, err := vm.New(program.Bytecode, vm.WithMagicHeader(program.MagicHeader)).Run()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result.ExitStatus != 1 || result.Instructions != 9 {
+		t.Fatalf("expected exit status 1 after 9 instructions, got %+v", result)
+	}
+}
+
+func TestAssembleData(t *testing.T) {
+	t.Parallel()
+
+	program, err := AssembleFile("data.s", `
+		main:
+			LoadImmediate r0, second
+			LoadMemory r1, r0
+			HaltRegister r1
+		.data 0x10
+		table: .word 1, -2, 'a'
+		second:
+		       .word main, table
+		.data 0x30
+		hello: .word "Hi"
+		.word 7
+	`)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expectedData := []vm.DataSegment{
+		{Address: 0x10, Values: []int64{1, -2, 'a', 0, 0x10}},
+		{Address: 0x30, Values: []int64{'H', 'i', 7}},
+	}
+
+	if !reflect.DeepEqual(program.Data, expectedData) {
+		t.Fatalf("expected data to be %v, got %v", expectedData, program.Data)
+	}
+
+	if symbols := program.Symbols.Symbols(); len(symbols) != 1 || symbols[0].Name != "main" {
+		t.Fatalf("expected only the code labels to be symbols, got %v", symbols)
+	}
+
+	options := []vm.Option{}
+
+	for _, segment := range program.Data {
+		options = append(options, vm.WithHeapImage(segment.Address, segment.Values))
+	}
+
+	result, err := vm.New(program.Bytecode, options...).Run()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result.ExitStatus != 0 {
+		t.Fatalf("expected exit status 0, got %d", result.ExitStatus)
+	}
+}
+
+func TestAssembleErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		src      string
+		expected error
+		message  string
+	}{
+		{
+			name:     "unknown mnemonic",
+			src:      "  Jump 10",
+			expected: ErrUnknownMnemonic,
+			message:  "1:3: unknown mnemonic: Jump",
+		},
+		{
+			name:     "unknown directive",
+			src:      ".text",
+			expected: ErrUnknownDirective,
+			message:  "1:1: unknown directive: .text",
+		},
+		{
+			name:     "operand count",
+			src:      "Nop\nAdd r0, r1",
+			expected: ErrOperandCount,
+			message:  "2:1: wrong number of operands: Add takes 3, got 2",
+		},
+		{
+			name:     "register out of range",
+			src:      "Push r32",
+			expected: ErrInvalidOperand,
+			message:  "1:6: invalid operand: expected a register, got r32",
+		},
+		{
+			name:     "register as immediate",
+			src:      "LoadImmediate r0, r1",
+			expected: ErrInvalidOperand,
+			message:  "1:19: invalid operand: expected a number or label, got r1",
+		},
+		{
+			name:     "count out of range",
+			src:      "HostCall 0, r0, 32",
+			expected: ErrInvalidOperand,
+			message:  "1:17: invalid operand: expected a count from 0 to 31, got 32",
+		},
+		{
+			name:     "invalid character",
+			src:      "LoadImmediate r0, 'ab'",
+			expected: ErrInvalidOperand,
+			message:  "1:19: invalid operand: invalid character 'ab'",
+		},
+		{
+			name:     "invalid number",
+			src:      "JmpImmediate 12x",
+			expected: ErrInvalidOperand,
+			message:  "1:14: invalid operand: invalid number 12x",
+		},
+		{
+			name:     "unknown label",
+			src:      "JmpImmediate nowhere",
+			expected: ErrUnknownLabel,
+			message:  "1:14: unknown label: nowhere",
+		},
+		{
+			name:     "duplicate label",
+			src:      "a: Nop\n  a: Nop",
+			expected: ErrDuplicateLabel,
+			message:  "2:3: duplicate label: a",
+		},
+		{
+			name:     "register as label",
+			src:      "r1: Nop",
+			expected: ErrSyntax,
+			message:  "1:1: syntax error: invalid label name \"r1\"",
+		},
+		{
+			name:     "unterminated literal",
+			src:      "LoadImmediate r0, 'a",
+			expected: ErrSyntax,
+			message:  "1:19: syntax error: unterminated literal",
+		},
+		{
+			name:     "missing comma",
+			src:      "Add r0 r1, r2",
+			expected: ErrSyntax,
+			message:  "1:8: syntax error: expected a comma",
+		},
+		{
+			name:     "trailing comma",
+			src:      "Push r0,",
+			expected: ErrSyntax,
+			message:  "1:8: syntax error: expected an operand after the comma",
+		},
+		{
+			name:     "magic after instruction",
+			src:      "Nop\n.magic 1",
+			expected: ErrSyntax,
+			message:  "2:1: syntax error: .magic must come before any instruction, and only once",
+		},
+		{
+			name:     "data without address",
+			src:      ".data",
+			expected: ErrOperandCount,
+			message:  "1:1: wrong number of operands: .data takes 1, got 0",
+		},
+		{
+			name:     "data with label address",
+			src:      "a: .data a",
+			expected: ErrInvalidOperand,
+			message:  "1:10: invalid operand: expected a heap address, got a",
+		},
+		{
+			name:     "word without values",
+			src:      ".word",
+			expected: ErrOperandCount,
+			message:  "1:1: wrong number of operands: .word takes at least 1",
+		},
+		{
+			name:     "word with register",
+			src:      ".word 1, r1",
+			expected: ErrInvalidOperand,
+			message:  "1:10: invalid operand: expected a number or label, got r1",
+		},
+		{
+			name:     "magic byte out of range",
+			src:      ".magic 256",
+			expected: ErrInvalidOperand,
+			message:  "1:8: invalid operand: expected a string or byte, got 256",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Assemble(test.src)
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+
+			if err.Error() != test.message {
+				t.Fatalf("expected error message to be \"%s\", got \"%s\"", test.message, err.Error())
+			}
+		})
+	}
+
+	_, err := Assemble("Jump\nPush x")
+
+	var asmErr *Error
+
+	if !errors.As(err, &asmErr) || asmErr.Line != 1 || !errors.Is(err, ErrInvalidOperand) {
+		t.Fatalf("expected every error to be reported, got %v", err)
+	}
+}
+
+func TestIsLabelName(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]bool{
+		"main":    true,
+		"_loop.2": true,
+		"r32":     true,
+		"r31":     false,
+		"R0":      false,
+		"2nd":     false,
+		"a-b":     false,
+		"":        false,
+	} {
+		if IsLabelName(name) != expected {
+			t.Fatalf("expected IsLabelName(%q) to be %t", name, expected)
+		}
+	}
+}
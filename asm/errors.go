@@ -0,0 +1,55 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownMnemonic is the kind of error for an instruction that does
+	// not exist.
+	ErrUnknownMnemonic = errors.New("unknown mnemonic")
+	// ErrUnknownDirective is the kind of error for a directive that does
+	// not exist.
+	ErrUnknownDirective = errors.New("unknown directive")
+	// ErrOperandCount is the kind of error for an instruction or directive
+	// with the wrong number of operands.
+	ErrOperandCount = errors.New("wrong number of operands")
+	// ErrInvalidOperand is the kind of error for an operand that cannot be
+	// used in its position.
+	ErrInvalidOperand = errors.New("invalid operand")
+	// ErrUnknownLabel is the kind of error for a reference to a label that
+	// is not defined.
+	ErrUnknownLabel = errors.New("unknown label")
+	// ErrDuplicateLabel is the kind of error for a label that is defined
+	// more than once.
+	ErrDuplicateLabel = errors.New("duplicate label")
+	// ErrSyntax is the kind of error for a line that cannot be parsed.
+	ErrSyntax = errors.New("syntax error")
+)
+
+// Error describes an error at a position in the source code.
+type Error struct {
+	// The kind of error, which is one of the Err* variables.
+	Err error
+	// The details of the error.
+	Detail string
+	// The line of the error, starting at 1.
+	Line int
+	// The column of the error, in bytes, starting at 1.
+	Column int
+}
+
+// Error returns the error message, with the line and column.
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Err.Error())
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Err.Error(), e.Detail)
+}
+
+// Unwrap returns the kind of error, for use with errors.Is.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,107 @@
+package asm
+
+import (
+	"strings"
+)
+
+// tokenKind defines the kind of a token.
+type tokenKind byte
+
+const (
+	// tokenWord is a mnemonic, directive, label, register or number.
+	tokenWord tokenKind = iota
+	// tokenString is a string literal, including the double quotes.
+	tokenString
+	// tokenChar is a character literal, including the single quotes.
+	tokenChar
+	// tokenComma separates operands.
+	tokenComma
+	// tokenColon ends a label definition.
+	tokenColon
+)
+
+// token defines a single token on a line of source code.
+type token struct {
+	// The kind of the token.
+	kind tokenKind
+	// The text of the token.
+	text string
+	// The column of the token, in bytes, starting at 1.
+	col int
+}
+
+// commentStart starts a comment, which runs until the end of the line.
+const commentStart = ';'
+
+// tokenize splits a line of source code into tokens.
+// Comments are left out.
+func tokenize(line string, lineNum int) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(line); {
+		c := line[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case c == commentStart:
+			return tokens, nil
+
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", col: i + 1})
+			i++
+
+		case c == ':':
+			tokens = append(tokens, token{kind: tokenColon, text: ":", col: i + 1})
+			i++
+
+		case c == '"' || c == '\'':
+			end := quoteEnd(line, i)
+
+			if end == -1 {
+				return nil, &Error{
+					Err:    ErrSyntax,
+					Detail: "unterminated literal",
+					Line:   lineNum,
+					Column: i + 1,
+				}
+			}
+
+			kind := tokenString
+
+			if c == '\'' {
+				kind = tokenChar
+			}
+
+			tokens = append(tokens, token{kind: kind, text: line[i:end], col: i + 1})
+			i = end
+
+		default:
+			end := i + strings.IndexAny(line[i:]+" ", " \t\r,:;\"'")
+
+			tokens = append(tokens, token{kind: tokenWord, text: line[i:end], col: i + 1})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// quoteEnd returns the index right after the closing quote of the literal
+// that starts at start, or -1 when the literal is not terminated.
+func quoteEnd(line string, start int) int {
+	quote := line[start]
+
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+
+		case quote:
+			return i + 1
+		}
+	}
+
+	return -1
+}
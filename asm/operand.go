@@ -0,0 +1,82 @@
+package asm
+
+import (
+	"strconv"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// parseRegister parses a register, such as r0 or R31.
+func parseRegister(text string) (int64, bool) {
+	if len(text) < 2 || (text[0] != 'r' && text[0] != 'R') {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(text[1:], 10, 8)
+
+	if err != nil || n >= vm.NumRegisters {
+		return 0, false
+	}
+
+	return int64(n), true
+}
+
+// parseNumber parses a decimal, hexadecimal, octal or binary number.
+// Positive numbers may use the full 64 bits, so 0xFFFFFFFFFFFFFFFF is -1.
+func parseNumber(text string) (int64, bool) {
+	if strings.HasPrefix(text, "-") {
+		n, err := strconv.ParseInt(text, 0, 64)
+
+		return n, err == nil
+	}
+
+	n, err := strconv.ParseUint(strings.TrimPrefix(text, "+"), 0, 64)
+
+	return int64(n), err == nil // #nosec: G115
+}
+
+// parseChar parses a character literal, such as 'a' or '\n'.
+func parseChar(text string) (int64, bool) {
+	s, err := strconv.Unquote(text)
+
+	if err != nil {
+		return 0, false
+	}
+
+	runes := []rune(s)
+
+	if len(runes) != 1 {
+		return 0, false
+	}
+
+	return int64(runes[0]), true
+}
+
+// IsLabelName returns whether name can be defined as a label.
+// Label names consist of letters, digits, underscores and dots,
+// do not start with a digit, and are not the name of a register.
+func IsLabelName(name string) bool {
+	_, isRegister := parseRegister(name)
+
+	return !isRegister && isLabel(name)
+}
+
+// isLabel returns whether text can be the name of a label,
+// without checking whether it is the name of a register.
+func isLabel(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for i, c := range text {
+		isLetter := c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+
+		if !isLetter && (i == 0 || !isDigit) {
+			return false
+		}
+	}
+
+	return true
+}
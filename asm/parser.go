@@ -0,0 +1,108 @@
+package asm
+
+import (
+	"strings"
+)
+
+// statement defines a single parsed line of source code.
+type statement struct {
+	// The line of the statement, starting at 1.
+	line int
+	// The labels that are defined on the line.
+	labels []token
+	// The mnemonic or directive. This is empty for a line with only labels.
+	name token
+	// The operands of the instruction or directive.
+	operands []token
+}
+
+// parse splits the source code into statements.
+// Lines without labels, instructions or directives are left out.
+func parse(src string) ([]statement, []error) {
+	var statements []statement
+
+	var errs []error
+
+	for i, line := range strings.Split(src, "\n") {
+		lineNum := i + 1
+		tokens, err := tokenize(line, lineNum)
+
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		stmt, err := parseStatement(tokens, lineNum)
+
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		statements = append(statements, stmt)
+	}
+
+	return statements, errs
+}
+
+func parseStatement(tokens []token, lineNum int) (statement, error) {
+	stmt := statement{
+		line:     lineNum,
+		labels:   nil,
+		name:     token{kind: tokenWord, text: "", col: 0},
+		operands: nil,
+	}
+
+	i := 0
+
+	for i+1 < len(tokens) && tokens[i].kind == tokenWord && tokens[i+1].kind == tokenColon {
+		stmt.labels = append(stmt.labels, tokens[i])
+		i += 2
+	}
+
+	if i == len(tokens) {
+		return stmt, nil
+	}
+
+	if tokens[i].kind != tokenWord {
+		return stmt, syntaxError(tokens[i], lineNum, "expected a mnemonic, directive or label")
+	}
+
+	stmt.name = tokens[i]
+	i++
+
+	for i < len(tokens) {
+		if tokens[i].kind == tokenComma || tokens[i].kind == tokenColon {
+			return stmt, syntaxError(tokens[i], lineNum, "expected an operand")
+		}
+
+		stmt.operands = append(stmt.operands, tokens[i])
+		i++
+
+		if i == len(tokens) {
+			break
+		}
+
+		if tokens[i].kind != tokenComma {
+			return stmt, syntaxError(tokens[i], lineNum, "expected a comma")
+		}
+
+		i++
+
+		if i == len(tokens) {
+			return stmt, syntaxError(tokens[i-1], lineNum, "expected an operand after the comma")
+		}
+	}
+
+	return stmt, nil
+}
+
+func syntaxError(tok token, lineNum int, detail string) error {
+	return &Error{Err: ErrSyntax, Detail: detail, Line: lineNum, Column: tok.col}
+}
@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// binaryReader reads big-endian values from binary data,
+// such as a snapshot or an image,
+// and remembers the first error that occurred.
+type binaryReader struct {
+	// The data that has not been read yet.
+	data []byte
+	// The first error that occurred.
+	err error
+	// The name of the format, which is used in error messages.
+	name string
+	// The error that all errors wrap, such as ErrInvalidSnapshot.
+	invalid error
+}
+
+func newBinaryReader(data []byte, name string, invalid error) *binaryReader {
+	return &binaryReader{data: data, err: nil, name: name, invalid: invalid}
+}
+
+func (r *binaryReader) fail(msg string) {
+	if r.err != nil {
+		return
+	}
+
+	r.err = fmt.Errorf("%w: %s", r.invalid, msg)
+}
+
+// failEnd records that the data ended before all values were read.
+func (r *binaryReader) failEnd() {
+	r.fail("unexpected end of " + r.name)
+}
+
+func (r *binaryReader) next(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if uint64(len(r.data)) < n {
+		r.failEnd()
+
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+
+	return b
+}
+
+func (r *binaryReader) byte() byte {
+	b := r.next(1)
+
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
+}
+
+func (r *binaryReader) uint16() uint16 {
+	b := r.next(2)
+
+	if b == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *binaryReader) uint64() uint64 {
+	b := r.next(8)
+
+	if b == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(b)
+}
+
+// values reads n values, after checking that the snapshot is large enough.
+func (r *binaryReader) values(n uint64) []int64 {
+	if r.err != nil {
+		return nil
+	}
+
+	if n > uint64(len(r.data))/8 {
+		r.failEnd()
+
+		return nil
+	}
+
+	values := make([]int64, n)
+
+	for i := range values {
+		values[i] = int64(r.uint64()) // #nosec: G115
+	}
+
+	return values
+}
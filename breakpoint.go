@@ -0,0 +1,58 @@
+package vm
+
+import (
+	"maps"
+	"slices"
+)
+
+// SetBreakpoint makes Run and RunContext pause before executing the
+// instruction at pc. Step does not stop at breakpoints.
+//
+// When the VM is resumed, the instruction at the breakpoint is executed,
+// without pausing at the same breakpoint again.
+func (v *VM) SetBreakpoint(pc register) error {
+	if pc >= v.programLen {
+		return ErrOutOfBounds
+	}
+
+	if v.breakpoints == nil {
+		v.breakpoints = map[register]struct{}{}
+	}
+
+	v.breakpoints[pc] = struct{}{}
+
+	return nil
+}
+
+// ClearBreakpoint removes the breakpoint at pc, if there is one.
+func (v *VM) ClearBreakpoint(pc register) {
+	delete(v.breakpoints, pc)
+}
+
+// Breakpoints returns the addresses of all breakpoints, sorted by address.
+func (v *VM) Breakpoints() []register {
+	return slices.Sorted(maps.Keys(v.breakpoints))
+}
+
+func (v *VM) hitBreakpoint() bool {
+	if v.skipBreakpoint {
+		v.skipBreakpoint = false
+
+		return false
+	}
+
+	if _, hasBreakpoint := v.breakpoints[v.pc]; !hasBreakpoint {
+		return false
+	}
+
+	v.pause = Pause{
+		Reason:  PauseReasonBreakpoint,
+		PC:      v.pc,
+		Address: 0,
+		Access:  0,
+	}
+
+	v.skipBreakpoint = true
+
+	return true
+}
@@ -0,0 +1,253 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var debugProgram = []byte{
+	byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 5,
+	byte(OpcodeStoreMemory), 0, 1,
+	byte(OpcodeLoadMemory), 2, 1,
+	byte(OpcodeHalt),
+}
+
+func TestBreakpointsAndWatchpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(vm *VM) error
+		expected []Pause
+	}{
+		{
+			name: "breakpoint",
+			setup: func(vm *VM) error {
+				return vm.SetBreakpoint(10)
+			},
+			expected: []Pause{
+				{Reason: PauseReasonBreakpoint, PC: 10, Address: 0, Access: 0},
+			},
+		},
+		{
+			name: "cleared breakpoint",
+			setup: func(vm *VM) error {
+				err := vm.SetBreakpoint(10)
+				vm.ClearBreakpoint(10)
+
+				return err
+			},
+			expected: []Pause{},
+		},
+		{
+			name: "write watchpoint",
+			setup: func(vm *VM) error {
+				return vm.SetWatchpoint(0, WatchWrite)
+			},
+			expected: []Pause{
+				{Reason: PauseReasonWatchpoint, PC: 10, Address: 0, Access: WatchWrite},
+			},
+		},
+		{
+			name: "read watchpoint",
+			setup: func(vm *VM) error {
+				return vm.SetWatchpoint(0, WatchRead)
+			},
+			expected: []Pause{
+				{Reason: PauseReasonWatchpoint, PC: 13, Address: 0, Access: WatchRead},
+			},
+		},
+		{
+			name: "read and write watchpoint with breakpoints",
+			setup: func(vm *VM) error {
+				return errors.Join(
+					vm.SetWatchpoint(0, WatchReadWrite),
+					vm.SetBreakpoint(0),
+					vm.SetBreakpoint(16),
+				)
+			},
+			expected: []Pause{
+				{Reason: PauseReasonBreakpoint, PC: 0, Address: 0, Access: 0},
+				{Reason: PauseReasonWatchpoint, PC: 10, Address: 0, Access: WatchWrite},
+				{Reason: PauseReasonWatchpoint, PC: 13, Address: 0, Access: WatchRead},
+				{Reason: PauseReasonBreakpoint, PC: 16, Address: 0, Access: 0},
+			},
+		},
+		{
+			name: "cleared watchpoint",
+			setup: func(vm *VM) error {
+				err := vm.SetWatchpoint(0, WatchReadWrite)
+				vm.ClearWatchpoint(0)
+
+				return err
+			},
+			expected: []Pause{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			vm := New(debugProgram)
+
+			if err := test.setup(vm); err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			pauses := []Pause{}
+
+			for {
+				result, err := vm.Run()
+
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err.Error())
+				}
+
+				if result.Reason != StopReasonPaused {
+					break
+				}
+
+				pauses = append(pauses, result.Pause)
+			}
+
+			if !reflect.DeepEqual(pauses, test.expected) {
+				t.Fatalf("expected pauses to be %+v, got %+v", test.expected, pauses)
+			}
+
+			if vm.InstructionCount() != 4 || vm.registers[2] != 5 {
+				t.Fatalf("expected every instruction to run once, got %d", vm.InstructionCount())
+			}
+		})
+	}
+}
+
+func TestBreakpointResume(t *testing.T) {
+	t.Parallel()
+
+	vm := New(debugProgram)
+
+	if err := vm.SetBreakpoint(10); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result, _ := vm.Run(); result.Reason != StopReasonPaused || vm.PC() != 10 {
+		t.Fatalf("expected to pause at pc 10, got %+v at pc %d", result, vm.PC())
+	}
+
+	if info, err := vm.Step(); err != nil || info.PC != 10 {
+		t.Fatalf("expected Step to execute the instruction at the breakpoint, got %+v", info)
+	}
+
+	if err := vm.SetBreakpoint(13); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result, _ := vm.Run(); result.Reason != StopReasonHalted {
+		t.Fatalf("expected not to pause at the pc that Step stopped at, got %+v", result)
+	}
+
+	vm.Reset(false)
+
+	if err := vm.SetBreakpoint(10); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result, _ := vm.Run(); result.Reason != StopReasonPaused || vm.PC() != 10 {
+		t.Fatalf("expected to pause at pc 10 after a reset, got %+v", result)
+	}
+
+	clone := vm.Clone()
+	clone.ClearBreakpoint(10)
+
+	if !reflect.DeepEqual(vm.Breakpoints(), []register{10}) {
+		t.Fatalf("expected breakpoints of the original to be kept, got %v", vm.Breakpoints())
+	}
+
+	if result, _ := vm.Run(); result.Reason != StopReasonHalted {
+		t.Fatalf("expected to resume until halted, got %+v", result)
+	}
+}
+
+func TestBreakpointResumeInterrupted(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		interrupt func(vm *VM) error
+	}{
+		{
+			name: "out of fuel in run",
+			interrupt: func(vm *VM) error {
+				_, err := vm.Run()
+
+				return err
+			},
+		},
+		{
+			name: "out of fuel in step",
+			interrupt: func(vm *VM) error {
+				_, err := vm.Step()
+
+				return err
+			},
+		},
+		{
+			name: "canceled",
+			interrupt: func(vm *VM) error {
+				_, err := vm.RunContext(canceled)
+
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			vm := New(debugProgram, WithFuel(1, nil))
+
+			if err := vm.SetBreakpoint(10); err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if result, _ := vm.Run(); result.Reason != StopReasonPaused || vm.PC() != 10 {
+				t.Fatalf("expected to pause at pc 10, got %+v at pc %d", result, vm.PC())
+			}
+
+			if err := test.interrupt(vm); err == nil || vm.PC() != 10 {
+				t.Fatalf("expected to be interrupted at pc 10, got %v at pc %d", err, vm.PC())
+			}
+
+			vm.AddFuel(10)
+
+			if result, err := vm.Run(); err != nil || result.Reason != StopReasonHalted {
+				t.Fatalf("expected not to pause at the breakpoint again, got %+v and %v", result, err)
+			}
+		})
+	}
+}
+
+func TestBreakpointsAndWatchpointsErr(t *testing.T) {
+	t.Parallel()
+
+	vm := New(debugProgram, WithHeapSize(16))
+
+	if err := vm.SetBreakpoint(register(len(debugProgram))); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfBounds, err)
+	}
+
+	if err := vm.SetWatchpoint(16, WatchRead); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfBounds, err)
+	}
+
+	if len(vm.Breakpoints()) != 0 || len(vm.Watchpoints()) != 0 {
+		t.Fatalf("expected no breakpoints or watchpoints to be set")
+	}
+}
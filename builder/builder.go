@@ -0,0 +1,179 @@
+// Package builder creates vee-em bytecode from Go,
+// without writing assembly source code or raw bytes:
+//
+//	b := builder.New(nil)
+//	b.LoadImmediate(builder.R0, 10)
+//	b.LoadImmediate(builder.R1, 1)
+//	b.Label("loop")
+//	b.Sub(builder.R0, builder.R0, builder.R1)
+//	b.JmpImmediateIfNotZero(builder.R0, "loop")
+//	b.Halt()
+//
+//	program, err := b.Bytes()
+//
+// Labels may be used before they are defined,
+// and are patched in when the bytecode is created.
+package builder
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// Register defines the index of a register.
+type Register byte
+
+// The registers of the VM.
+const (
+	R0 Register = iota
+	R1
+	R2
+	R3
+	R4
+	R5
+	R6
+	R7
+	R8
+	R9
+	R10
+	R11
+	R12
+	R13
+	R14
+	R15
+	R16
+	R17
+	R18
+	R19
+	R20
+	R21
+	R22
+	R23
+	R24
+	R25
+	R26
+	R27
+	R28
+	R29
+	R30
+	R31
+)
+
+// fixup defines an address operand that refers to a label.
+type fixup struct {
+	// The offset of the operand in the code.
+	offset int
+	// The label that the operand refers to.
+	label string
+}
+
+// Builder creates the bytecode of a program, one instruction at a time.
+// Errors are collected, and returned by Bytes.
+type Builder struct {
+	// The magic header, which comes before the code.
+	magicHeader []byte
+	// The code that has been written so far.
+	code []byte
+	// The absolute addresses of the labels.
+	labels map[string]uint64
+	// The labels, in order of definition.
+	symbols []vm.Symbol
+	// The address operands to patch in Bytes.
+	fixups []fixup
+	// The errors that have been found so far.
+	errs []error
+}
+
+// New creates a new builder.
+// The magic header is written before the code,
+// and is included in the addresses of labels.
+func New(magicHeader []byte) *Builder {
+	return &Builder{
+		magicHeader: append([]byte{}, magicHeader...),
+		code:        []byte{},
+		labels:      map[string]uint64{},
+		symbols:     nil,
+		fixups:      nil,
+		errs:        nil,
+	}
+}
+
+// Address returns the absolute address of the next instruction.
+func (b *Builder) Address() uint64 {
+	return uint64(len(b.magicHeader) + len(b.code))
+}
+
+// Label defines a label at the address of the next instruction.
+func (b *Builder) Label(name string) {
+	if _, isDefined := b.labels[name]; isDefined {
+		b.errs = append(b.errs, fmt.Errorf("%w: %s", ErrDuplicateLabel, name))
+
+		return
+	}
+
+	b.labels[name] = b.Address()
+	b.symbols = append(b.symbols, vm.Symbol{Name: name, Address: b.Address()})
+}
+
+// Symbols returns the labels that have been defined so far.
+func (b *Builder) Symbols() *vm.SymbolTable {
+	return vm.NewSymbolTable(b.symbols)
+}
+
+// Bytes returns the bytecode of the program, starting with the magic header,
+// with the labels patched in.
+// When any instruction was invalid, or a label is not defined,
+// all errors are returned, joined.
+func (b *Builder) Bytes() ([]byte, error) {
+	program := append(append([]byte{}, b.magicHeader...), b.code...)
+	errs := b.errs
+
+	for _, f := range b.fixups {
+		addr, isDefined := b.labels[f.label]
+
+		if !isDefined {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownLabel, f.label))
+
+			continue
+		}
+
+		binary.BigEndian.PutUint64(program[len(b.magicHeader)+f.offset:], addr)
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return program, nil
+}
+
+// emit writes an instruction with register operands only.
+func (b *Builder) emit(opcode vm.Opcode, registers ...Register) {
+	b.code = append(b.code, byte(opcode))
+	b.registers(registers...)
+}
+
+// registers writes register operands.
+func (b *Builder) registers(registers ...Register) {
+	for _, r := range registers {
+		if r >= vm.NumRegisters {
+			b.errs = append(b.errs, fmt.Errorf("%w: r%d", ErrInvalidRegister, r))
+		}
+
+		b.code = append(b.code, byte(r))
+	}
+}
+
+// immediate writes an immediate operand.
+func (b *Builder) immediate(value int64) {
+	b.code = binary.BigEndian.AppendUint64(b.code, uint64(value)) // #nosec: G115
+}
+
+// address writes an address operand, which is patched in by Bytes.
+func (b *Builder) address(label string) {
+	b.fixups = append(b.fixups, fixup{offset: len(b.code), label: label})
+	b.code = binary.BigEndian.AppendUint64(b.code, 0)
+}
@@ -0,0 +1,217 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	b := New([]byte{0xAB})
+	b.LoadImmediate(R0, 3)
+	b.LoadImmediate(R1, 1)
+	b.LoadImmediate(R2, 0)
+	b.Label("loop")
+	b.CallImmediate("increment")
+	b.Sub(R0, R0, R1)
+	b.JmpImmediateIfNotZero(R0, "loop")
+	b.HaltRegister(R2)
+	b.Label("increment")
+	b.Add(R2, R2, R1)
+	b.Return()
+
+	program, err := b.Bytes()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := []byte{
+		0xAB,
+		byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 3,
+		byte(vm.OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(vm.OpcodeLoadImmediate), 2, 0, 0, 0, 0, 0, 0, 0, 0,
+		byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 56,
+		byte(vm.OpcodeSub), 0, 0, 1,
+		byte(vm.OpcodeJmpImmediateIfNotZero), 0, 0, 0, 0, 0, 0, 0, 0, 31,
+		byte(vm.OpcodeHaltRegister), 2,
+		byte(vm.OpcodeAdd), 2, 2, 1,
+		byte(vm.OpcodeReturn),
+	}
+
+	if !bytes.Equal(program, expected) {
+		t.Fatalf("expected %v, got %v", expected, program)
+	}
+
+	if addr, _ := b.Symbols().Address("increment"); addr != 56 || b.Address() != 61 {
+		t.Fatalf("expected \"increment\" at 56 and the end at 61, got %d and %d", addr, b.Address())
+	}
+
+	result, err := vm.New(program, vm.WithMagicHeader([]byte{0xAB})).Run()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result.ExitStatus != 3 {
+		t.Fatalf("expected exit status 3, got %d", result.ExitStatus)
+	}
+}
+
+func TestBuilderInstructions(t *testing.T) {
+	t.Parallel()
+
+	b := New(nil)
+	b.Label("start")
+
+	tests := []struct {
+		emit     func()
+		expected string
+	}{
+		{func() { b.Nop() }, "Nop"},
+		{func() { b.Push(R1) }, "Push r1"},
+		{func() { b.Pop(R2) }, "Pop r2"},
+		{func() { b.LoadImmediate(R3, -42) }, "LoadImmediate r3, -42"},
+		{func() { b.LoadLabel(R4, "start") }, "LoadImmediate r4, 0"},
+		{func() { b.LoadRegister(R5, R6) }, "LoadRegister r5, r6"},
+		{func() { b.LoadMemory(R7, R8) }, "LoadMemory r7, r8"},
+		{func() { b.StoreMemory(R9, R10) }, "StoreMemory r9, r10"},
+		{func() { b.Add(R0, R1, R2) }, "Add r0, r1, r2"},
+		{func() { b.Sub(R0, R1, R2) }, "Sub r0, r1, r2"},
+		{func() { b.Mul(R0, R1, R2) }, "Mul r0, r1, r2"},
+		{func() { b.Div(R0, R1, R2) }, "Div r0, r1, r2"},
+		{func() { b.Mod(R0, R1, R2) }, "Mod r0, r1, r2"},
+		{func() { b.AND(R0, R1, R2) }, "AND r0, r1, r2"},
+		{func() { b.OR(R0, R1, R2) }, "OR r0, r1, r2"},
+		{func() { b.XOR(R0, R1, R2) }, "XOR r0, r1, r2"},
+		{func() { b.NOT(R11, R12) }, "NOT r11, r12"},
+		{func() { b.ShiftLeft(R0, R1, R2) }, "ShiftLeft r0, r1, r2"},
+		{func() { b.ShiftRight(R0, R1, R2) }, "ShiftRight r0, r1, r2"},
+		{func() { b.ShiftRightArithmetic(R0, R1, R2) }, "ShiftRightArithmetic r0, r1, r2"},
+		{func() { b.CMP(R13, R14) }, "CMP r13, r14"},
+		{func() { b.JmpImmediate("start") }, "JmpImmediate 0x0000"},
+		{func() { b.JmpImmediateIfZero(R15, "start") }, "JmpImmediateIfZero r15, 0x0000"},
+		{func() { b.JmpImmediateIfNotZero(R16, "start") }, "JmpImmediateIfNotZero r16, 0x0000"},
+		{func() { b.JmpImmediateIfEqual("start") }, "JmpImmediateIfEqual 0x0000"},
+		{func() { b.JmpImmediateIfNotEqual("start") }, "JmpImmediateIfNotEqual 0x0000"},
+		{func() { b.JmpImmediateIfGreater("start") }, "JmpImmediateIfGreater 0x0000"},
+		{func() { b.JmpImmediateIfGreaterOrEqual("start") }, "JmpImmediateIfGreaterOrEqual 0x0000"},
+		{func() { b.JmpImmediateIfLess("start") }, "JmpImmediateIfLess 0x0000"},
+		{func() { b.JmpImmediateIfLessOrEqual("start") }, "JmpImmediateIfLessOrEqual 0x0000"},
+		{func() { b.JmpRegister(R17) }, "JmpRegister r17"},
+		{func() { b.JmpRegisterIfZero(R18, R19) }, "JmpRegisterIfZero r18, r19"},
+		{func() { b.JmpRegisterIfNotZero(R20, R21) }, "JmpRegisterIfNotZero r20, r21"},
+		{func() { b.JmpRegisterIfEqual(R22) }, "JmpRegisterIfEqual r22"},
+		{func() { b.JmpRegisterIfNotEqual(R23) }, "JmpRegisterIfNotEqual r23"},
+		{func() { b.JmpRegisterIfGreater(R24) }, "JmpRegisterIfGreater r24"},
+		{func() { b.JmpRegisterIfGreaterOrEqual(R25) }, "JmpRegisterIfGreaterOrEqual r25"},
+		{func() { b.JmpRegisterIfLess(R26) }, "JmpRegisterIfLess r26"},
+		{func() { b.JmpRegisterIfLessOrEqual(R27) }, "JmpRegisterIfLessOrEqual r27"},
+		{func() { b.CallImmediate("start") }, "CallImmediate 0x0000"},
+		{func() { b.CallRegister(R28) }, "CallRegister r28"},
+		{func() { b.Return() }, "Return"},
+		{func() { b.HostCall(7, R29, 2) }, "HostCall 7, r29, 2"},
+		{func() { b.Halt() }, "Halt"},
+		{func() { b.HaltRegister(R31) }, "HaltRegister r31"},
+	}
+
+	for _, test := range tests {
+		test.emit()
+	}
+
+	program, err := b.Bytes()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	instructions, err := disasm.Disassemble(program, 0)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(instructions) != len(tests) {
+		t.Fatalf("expected %d instructions, got %d", len(tests), len(instructions))
+	}
+
+	opcodes := map[vm.Opcode]bool{}
+
+	for i, instruction := range instructions {
+		if instruction.String() != tests[i].expected {
+			t.Fatalf("expected \"%s\", got \"%s\"", tests[i].expected, instruction.String())
+		}
+
+		opcodes[instruction.Opcode] = true
+	}
+
+	for opcode := range vm.Opcode(0xFF) {
+		if vm.GetOperandKinds(opcode) != nil && !opcodes[opcode] {
+			t.Fatalf("expected the builder to support %s", opcode)
+		}
+	}
+}
+
+func TestBuilderErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		build    func(b *Builder)
+		expected error
+		message  string
+	}{
+		{
+			name:     "invalid register",
+			build:    func(b *Builder) { b.Add(R0, Register(32), R1) },
+			expected: ErrInvalidRegister,
+			message:  "invalid register: r32",
+		},
+		{
+			name:     "invalid count",
+			build:    func(b *Builder) { b.HostCall(0, R0, 32) },
+			expected: ErrInvalidCount,
+			message:  "invalid register count: 32",
+		},
+		{
+			name:     "unknown label",
+			build:    func(b *Builder) { b.JmpImmediate("nowhere") },
+			expected: ErrUnknownLabel,
+			message:  "unknown label: nowhere",
+		},
+		{
+			name: "duplicate label",
+			build: func(b *Builder) {
+				b.Label("a")
+				b.Nop()
+				b.Label("a")
+			},
+			expected: ErrDuplicateLabel,
+			message:  "duplicate label: a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := New(nil)
+			test.build(b)
+
+			_, err := b.Bytes()
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+
+			if err.Error() != test.message {
+				t.Fatalf("expected error message to be \"%s\", got \"%s\"", test.message, err.Error())
+			}
+		})
+	}
+}
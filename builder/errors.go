@@ -0,0 +1,16 @@
+package builder
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInvalidRegister is returned for a register that does not exist.
+	ErrInvalidRegister = errors.New("invalid register")
+	// ErrInvalidCount is returned for a number of registers that does not fit.
+	ErrInvalidCount = errors.New("invalid register count")
+	// ErrUnknownLabel is returned for a label that is used, but never defined.
+	ErrUnknownLabel = errors.New("unknown label")
+	// ErrDuplicateLabel is returned for a label that is defined more than once.
+	ErrDuplicateLabel = errors.New("duplicate label")
+)
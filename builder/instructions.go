@@ -0,0 +1,254 @@
+package builder
+
+import (
+	"fmt"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// Nop does not do anything.
+func (b *Builder) Nop() {
+	b.emit(vm.OpcodeNop)
+}
+
+// Push pushes the value of src onto the stack.
+func (b *Builder) Push(src Register) {
+	b.emit(vm.OpcodePush, src)
+}
+
+// Pop pops a value off the stack into dest.
+func (b *Builder) Pop(dest Register) {
+	b.emit(vm.OpcodePop, dest)
+}
+
+// LoadImmediate loads an immediate value into dest.
+func (b *Builder) LoadImmediate(dest Register, value int64) {
+	b.emit(vm.OpcodeLoadImmediate, dest)
+	b.immediate(value)
+}
+
+// LoadLabel loads the address of a label into dest,
+// for use with JmpRegister and CallRegister.
+func (b *Builder) LoadLabel(dest Register, label string) {
+	b.emit(vm.OpcodeLoadImmediate, dest)
+	b.address(label)
+}
+
+// LoadRegister copies the value of src into dest.
+func (b *Builder) LoadRegister(dest Register, src Register) {
+	b.emit(vm.OpcodeLoadRegister, dest, src)
+}
+
+// LoadMemory loads the heap value at the address in addr into dest.
+func (b *Builder) LoadMemory(dest Register, addr Register) {
+	b.emit(vm.OpcodeLoadMemory, dest, addr)
+}
+
+// StoreMemory stores the value of src at the heap address in addr.
+func (b *Builder) StoreMemory(src Register, addr Register) {
+	b.emit(vm.OpcodeStoreMemory, src, addr)
+}
+
+// Add adds src1 and src2 into dest.
+func (b *Builder) Add(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeAdd, dest, src1, src2)
+}
+
+// Sub subtracts src2 from src1 into dest.
+func (b *Builder) Sub(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeSub, dest, src1, src2)
+}
+
+// Mul multiplies src1 and src2 into dest.
+func (b *Builder) Mul(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeMul, dest, src1, src2)
+}
+
+// Div divides src1 by src2 into dest.
+func (b *Builder) Div(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeDiv, dest, src1, src2)
+}
+
+// Mod takes src1 modulo src2 into dest.
+func (b *Builder) Mod(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeMod, dest, src1, src2)
+}
+
+// AND performs an AND on src1 and src2 into dest.
+func (b *Builder) AND(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeAND, dest, src1, src2)
+}
+
+// OR performs an OR on src1 and src2 into dest.
+func (b *Builder) OR(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeOR, dest, src1, src2)
+}
+
+// XOR performs an exclusive OR on src1 and src2 into dest.
+func (b *Builder) XOR(dest Register, src1 Register, src2 Register) {
+	b.emit(vm.OpcodeXOR, dest, src1, src2)
+}
+
+// NOT performs a bitwise NOT on src into dest.
+func (b *Builder) NOT(dest Register, src Register) {
+	b.emit(vm.OpcodeNOT, dest, src)
+}
+
+// ShiftLeft shifts src left by the value of amount into dest.
+func (b *Builder) ShiftLeft(dest Register, src Register, amount Register) {
+	b.emit(vm.OpcodeShiftLeft, dest, src, amount)
+}
+
+// ShiftRight shifts src right (logical) by the value of amount into dest.
+func (b *Builder) ShiftRight(dest Register, src Register, amount Register) {
+	b.emit(vm.OpcodeShiftRight, dest, src, amount)
+}
+
+// ShiftRightArithmetic shifts src right (arithmetic) by the value of amount into dest.
+func (b *Builder) ShiftRightArithmetic(dest Register, src Register, amount Register) {
+	b.emit(vm.OpcodeShiftRightArithmetic, dest, src, amount)
+}
+
+// CMP compares src1 and src2 and sets the flags.
+func (b *Builder) CMP(src1 Register, src2 Register) {
+	b.emit(vm.OpcodeCMP, src1, src2)
+}
+
+// JmpImmediate jumps to a label.
+func (b *Builder) JmpImmediate(label string) {
+	b.emit(vm.OpcodeJmpImmediate)
+	b.address(label)
+}
+
+// JmpImmediateIfZero jumps to a label if the value of check is zero.
+func (b *Builder) JmpImmediateIfZero(check Register, label string) {
+	b.emit(vm.OpcodeJmpImmediateIfZero, check)
+	b.address(label)
+}
+
+// JmpImmediateIfNotZero jumps to a label if the value of check is not zero.
+func (b *Builder) JmpImmediateIfNotZero(check Register, label string) {
+	b.emit(vm.OpcodeJmpImmediateIfNotZero, check)
+	b.address(label)
+}
+
+// JmpImmediateIfEqual jumps to a label if the flags indicate equality.
+func (b *Builder) JmpImmediateIfEqual(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfEqual)
+	b.address(label)
+}
+
+// JmpImmediateIfNotEqual jumps to a label if the flags indicate inequality.
+func (b *Builder) JmpImmediateIfNotEqual(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfNotEqual)
+	b.address(label)
+}
+
+// JmpImmediateIfGreater jumps to a label if the flags indicate greater than.
+func (b *Builder) JmpImmediateIfGreater(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfGreater)
+	b.address(label)
+}
+
+// JmpImmediateIfGreaterOrEqual jumps to a label if the flags indicate greater than or equal.
+func (b *Builder) JmpImmediateIfGreaterOrEqual(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfGreaterOrEqual)
+	b.address(label)
+}
+
+// JmpImmediateIfLess jumps to a label if the flags indicate less than.
+func (b *Builder) JmpImmediateIfLess(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfLess)
+	b.address(label)
+}
+
+// JmpImmediateIfLessOrEqual jumps to a label if the flags indicate less than or equal.
+func (b *Builder) JmpImmediateIfLessOrEqual(label string) {
+	b.emit(vm.OpcodeJmpImmediateIfLessOrEqual)
+	b.address(label)
+}
+
+// JmpRegister jumps to the address in addr.
+func (b *Builder) JmpRegister(addr Register) {
+	b.emit(vm.OpcodeJmpRegister, addr)
+}
+
+// JmpRegisterIfZero jumps to the address in addr if the value of check is zero.
+func (b *Builder) JmpRegisterIfZero(check Register, addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfZero, check, addr)
+}
+
+// JmpRegisterIfNotZero jumps to the address in addr if the value of check is not zero.
+func (b *Builder) JmpRegisterIfNotZero(check Register, addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfNotZero, check, addr)
+}
+
+// JmpRegisterIfEqual jumps to the address in addr if the flags indicate equality.
+func (b *Builder) JmpRegisterIfEqual(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfEqual, addr)
+}
+
+// JmpRegisterIfNotEqual jumps to the address in addr if the flags indicate inequality.
+func (b *Builder) JmpRegisterIfNotEqual(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfNotEqual, addr)
+}
+
+// JmpRegisterIfGreater jumps to the address in addr if the flags indicate greater than.
+func (b *Builder) JmpRegisterIfGreater(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfGreater, addr)
+}
+
+// JmpRegisterIfGreaterOrEqual jumps to the address in addr if the flags indicate greater than or equal.
+func (b *Builder) JmpRegisterIfGreaterOrEqual(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfGreaterOrEqual, addr)
+}
+
+// JmpRegisterIfLess jumps to the address in addr if the flags indicate less than.
+func (b *Builder) JmpRegisterIfLess(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfLess, addr)
+}
+
+// JmpRegisterIfLessOrEqual jumps to the address in addr if the flags indicate less than or equal.
+func (b *Builder) JmpRegisterIfLessOrEqual(addr Register) {
+	b.emit(vm.OpcodeJmpRegisterIfLessOrEqual, addr)
+}
+
+// CallImmediate calls the function at a label.
+func (b *Builder) CallImmediate(label string) {
+	b.emit(vm.OpcodeCallImmediate)
+	b.address(label)
+}
+
+// CallRegister calls the function at the address in addr.
+func (b *Builder) CallRegister(addr Register) {
+	b.emit(vm.OpcodeCallRegister, addr)
+}
+
+// Return returns from a function call.
+func (b *Builder) Return() {
+	b.emit(vm.OpcodeReturn)
+}
+
+// HostCall calls the host function with an index,
+// with the values of count registers starting at first as the arguments.
+func (b *Builder) HostCall(index int64, first Register, count int) {
+	b.emit(vm.OpcodeHostCall)
+	b.immediate(index)
+	b.registers(first)
+
+	if count < 0 || count >= vm.NumRegisters {
+		b.errs = append(b.errs, fmt.Errorf("%w: %d", ErrInvalidCount, count))
+	}
+
+	b.code = append(b.code, byte(count)) // #nosec: G115
+}
+
+// Halt stops execution of the VM.
+func (b *Builder) Halt() {
+	b.emit(vm.OpcodeHalt)
+}
+
+// HaltRegister stops execution of the VM, with the exit status in src.
+func (b *Builder) HaltRegister(src Register) {
+	b.emit(vm.OpcodeHaltRegister, src)
+}
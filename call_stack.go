@@ -0,0 +1,43 @@
+package vm
+
+// CallFrame describes a function call that has not returned yet.
+type CallFrame struct {
+	// The address of the call instruction.
+	CallSite register
+	// The address of the function that has been called.
+	Target register
+	// The address to continue at when the function returns.
+	ReturnAddress register
+}
+
+// CallFrames returns a copy of the call frames of the functions that are
+// currently being executed.
+// The first element is the outermost call, and the last element is the
+// innermost call.
+func (v *VM) CallFrames() []CallFrame {
+	return append([]CallFrame{}, v.callStack...)
+}
+
+// CallDepth returns the number of functions that are currently being executed.
+func (v *VM) CallDepth() uint64 {
+	return uint64(len(v.callStack))
+}
+
+func (v *VM) pushCallFrame(
+	callSite register,
+	target register,
+) error {
+	if uint64(len(v.callStack)) >= v.callStackSize {
+		return newFault(ErrCallStackOverflow)
+	}
+
+	v.callStack = append(v.callStack, CallFrame{
+		CallSite:      callSite,
+		Target:        target,
+		ReturnAddress: v.pc,
+	})
+
+	v.pc = target
+
+	return nil
+}
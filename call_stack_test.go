@@ -0,0 +1,66 @@
+package vm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCallFrames(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 10,
+		byte(OpcodeHalt),
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 29,
+		byte(OpcodeCallRegister), 0,
+		byte(OpcodeReturn),
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+		byte(OpcodeReturn),
+	}
+
+	vm := New(program)
+
+	for range 3 {
+		if _, err := vm.Step(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	expected := []CallFrame{
+		{CallSite: 0, Target: 10, ReturnAddress: 9},
+		{CallSite: 20, Target: 29, ReturnAddress: 22},
+	}
+
+	if !reflect.DeepEqual(vm.CallFrames(), expected) || vm.CallDepth() != 2 {
+		t.Fatalf("expected call frames to be %v, got %v", expected, vm.CallFrames())
+	}
+
+	snapshot, err := vm.Snapshot()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	restored := New(program)
+
+	if err = restored.Restore(snapshot); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(restored.CallFrames(), expected) {
+		t.Fatalf("expected restored call frames to be %v, got %v", expected, restored.CallFrames())
+	}
+
+	if _, err = restored.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if restored.CallDepth() != 0 || restored.sp != 0 {
+		t.Fatalf("expected all calls to return, got depth %d and sp %d", restored.CallDepth(), restored.sp)
+	}
+}
@@ -0,0 +1,43 @@
+package vm
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone creates an independent copy of the VM, including its options
+// and handlers, which can be run separately from the original.
+//
+// The program and the pages of the heap are shared between the VMs,
+// and a page is only copied when either VM writes to it.
+// This makes cloning cheap, regardless of the size of the heap.
+//
+// The trace hooks and the host call handler are shared as well,
+// so stateful hooks, such as those of WithTraceWriter, a profiler or a
+// coverage collector, receive the events of both VMs, and a handler that
+// closes over the original *VM keeps acting on the original.
+// Create the clone with New instead when it needs its own hooks or handler.
+//
+// Clone must not be called while the VM is running.
+func (v *VM) Clone() *VM {
+	clone := *v
+
+	clone.stack = slices.Clone(v.stack)
+	clone.callStack = slices.Clone(v.callStack)
+	clone.undoLog = slices.Clone(v.undoLog)
+	clone.breakpoints = maps.Clone(v.breakpoints)
+	clone.watchpoints = maps.Clone(v.watchpoints)
+	clone.heapPages = slices.Clone(v.heapPages)
+	clone.heapPagesShared = make([]bool, len(v.heapPagesShared))
+
+	for i, page := range v.heapPages {
+		if page == nil {
+			continue
+		}
+
+		v.heapPagesShared[i] = true
+		clone.heapPagesShared[i] = true
+	}
+
+	return &clone
+}
@@ -0,0 +1,96 @@
+package vm
+
+import (
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	// Stores register 1 at the address in register 0.
+	program := []byte{
+		byte(OpcodePush), 1,
+		byte(OpcodeStoreMemory), 1, 0,
+	}
+
+	vm := New(program)
+
+	if err := vm.WriteHeap(0, []int64{1, 2}); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	clone := vm.Clone()
+
+	_ = clone.SetRegister(1, 42)
+
+	if _, err := clone.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.pc != 0 || vm.sp != 0 || vm.loadHeap(0) != 1 {
+		t.Fatalf("expected the original VM to be unaffected, got pc %d, sp %d, heap %d", vm.pc, vm.sp, vm.loadHeap(0))
+	}
+
+	if clone.sp != 1 || clone.loadHeap(0) != 42 || clone.loadHeap(1) != 2 {
+		t.Fatalf("expected the clone to be updated, got sp %d, heap %d %d", clone.sp, clone.loadHeap(0), clone.loadHeap(1))
+	}
+
+	_ = vm.SetRegister(1, 7)
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.loadHeap(0) != 7 || clone.loadHeap(0) != 42 {
+		t.Fatalf("expected the heaps to be independent, got %d and %d", vm.loadHeap(0), clone.loadHeap(0))
+	}
+
+	if &vm.heapPages[0][0] == &clone.heapPages[0][0] {
+		t.Fatalf("expected the written page to be copied")
+	}
+}
+
+func TestCloneSharesHooksAndHandler(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 0, 1, 0,
+	}
+
+	numEvents := 0
+	numCalls := 0
+
+	vm := New(
+		program,
+		WithTraceHook(TraceHook{
+			Before: nil,
+			After: func(_ *TraceEvent) error {
+				numEvents++
+
+				return nil
+			},
+		}),
+		WithHostCallHandler(func(
+			_ int64,
+			_ register,
+			_ register,
+			_ [NumRegisters]int64,
+		) (int64, error) {
+			numCalls++
+
+			return 0, nil
+		}),
+	)
+
+	clone := vm.Clone()
+
+	for _, v := range []*VM{vm, clone} {
+		if _, err := v.Run(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	if numEvents != 2 || numCalls != 2 {
+		t.Fatalf("expected the hook and handler to be shared, got %d events and %d calls", numEvents, numCalls)
+	}
+}
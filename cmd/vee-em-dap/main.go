@@ -0,0 +1,29 @@
+// Command vee-em-dap is a Debug Adapter Protocol server for vee-em bytecode,
+// which lets editors such as VS Code and Neovim debug programs.
+//
+// The server talks to the editor over stdin and stdout.
+// A program is started with a "launch" request, with these arguments:
+//
+//	program      the path of the bytecode file
+//	magicHeader  the magic header of the program, in hexadecimal
+//	symbols      the path of a symbol table file, to name functions with
+//	sourceMap    the path of a source map file, to show source lines with
+//	stopOnEntry  whether to pause before the first instruction
+//
+// Without a source map, or for instructions that are not mapped,
+// a disassembly of the program is shown instead.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	err := newServer(os.Stdin, os.Stdout).serve()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
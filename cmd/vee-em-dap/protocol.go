@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// contentLengthHeader is the header that precedes every message.
+const contentLengthHeader = "Content-Length:"
+
+var errMissingContentLength = errors.New("missing Content-Length header")
+
+// request defines a Debug Adapter Protocol request from the client.
+type request struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// response defines a Debug Adapter Protocol response to a request.
+type response struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Success    bool   `json:"success"`
+	Command    string `json:"command"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+// event defines a Debug Adapter Protocol event that is sent to the client.
+type event struct {
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	Body  any    `json:"body,omitempty"`
+}
+
+// readMessage reads a single request, which is preceded by a
+// Content-Length header and an empty line.
+func readMessage(r *bufio.Reader) (request, error) {
+	var req request
+
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return req, fmt.Errorf("could not read header: %w", err)
+		}
+
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			break
+		}
+
+		value, isContentLength := strings.CutPrefix(line, contentLengthHeader)
+
+		if !isContentLength {
+			continue
+		}
+
+		length, err = strconv.Atoi(strings.TrimSpace(value))
+
+		if err != nil {
+			return req, fmt.Errorf("invalid Content-Length header: %w", err)
+		}
+	}
+
+	if length < 0 {
+		return req, errMissingContentLength
+	}
+
+	body := make([]byte, length)
+
+	_, err := io.ReadFull(r, body)
+
+	if err != nil {
+		return req, fmt.Errorf("could not read message: %w", err)
+	}
+
+	err = json.Unmarshal(body, &req)
+
+	if err != nil {
+		return req, fmt.Errorf("could not decode message: %w", err)
+	}
+
+	return req, nil
+}
+
+// writeMessage writes a single message, preceded by a Content-Length header.
+func writeMessage(w io.Writer, msg any) error {
+	body, err := json.Marshal(msg)
+
+	if err != nil {
+		return fmt.Errorf("could not encode message: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s %d\r\n\r\n%s", contentLengthHeader, len(body), body)
+
+	if err != nil {
+		return fmt.Errorf("could not write message: %w", err)
+	}
+
+	return nil
+}
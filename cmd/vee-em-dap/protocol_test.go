@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadMessageErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing header", input: "\r\n{}"},
+		{name: "invalid header", input: "Content-Length: x\r\n\r\n{}"},
+		{name: "truncated body", input: "Content-Length: 10\r\n\r\n{}"},
+		{name: "invalid body", input: "Content-Length: 2\r\n\r\n[]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := readMessage(bufio.NewReader(strings.NewReader(test.input))); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
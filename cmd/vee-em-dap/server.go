@@ -0,0 +1,606 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+// threadID is the ID of the only thread that the VM has.
+const threadID = 1
+
+var (
+	errNotLaunched    = errors.New("no program has been launched")
+	errRunning        = errors.New("the program is running")
+	errUnknownCommand = errors.New("unknown command")
+)
+
+// launchArguments defines the arguments of the launch request.
+type launchArguments struct {
+	Program     string `json:"program"`
+	MagicHeader string `json:"magicHeader"`
+	Symbols     string `json:"symbols"`
+	SourceMap   string `json:"sourceMap"`
+	StopOnEntry bool   `json:"stopOnEntry"`
+}
+
+// handler handles a request, and returns the body of its response.
+type handler func(s *server, args json.RawMessage) (any, error)
+
+var handlers = map[string]handler{
+	"initialize":              (*server).initialize,
+	"launch":                  (*server).launch,
+	"setBreakpoints":          (*server).setBreakpoints,
+	"setExceptionBreakpoints": (*server).ignore,
+	"configurationDone":       (*server).configurationDone,
+	"threads":                 (*server).threads,
+	"stackTrace":              (*server).stackTrace,
+	"scopes":                  (*server).scopes,
+	"variables":               (*server).variables,
+	"source":                  (*server).source,
+	"continue":                (*server).cont,
+	"next":                    (*server).next,
+	"stepIn":                  (*server).stepIn,
+	"stepOut":                 (*server).stepOut,
+	"pause":                   (*server).pause,
+	"disconnect":              (*server).disconnect,
+	"terminate":               (*server).disconnect,
+}
+
+// whileRunning defines the requests that are handled while the program runs.
+// Other requests fail until the program has stopped.
+var whileRunning = map[string]bool{
+	"threads":    true,
+	"pause":      true,
+	"disconnect": true,
+	"terminate":  true,
+}
+
+// server defines a Debug Adapter Protocol session.
+type server struct {
+	// Guards the fields below against the program that runs in the background.
+	mu sync.Mutex
+	// Waits for the program that runs in the background.
+	wg sync.WaitGroup
+	// Cancels the program that runs in the background.
+	// It is nil when the program is not running.
+	cancel context.CancelFunc
+	// The first error that occurred while reporting in the background.
+	err error
+
+	// Where requests are read from.
+	in *bufio.Reader
+	// Where responses and events are written to.
+	out io.Writer
+	// The sequence number of the last message that has been sent.
+	seq int
+	// The events to send after the response to the current request.
+	pending []event
+	// Whether the client has disconnected.
+	isDone bool
+
+	// The VM that is being debugged.
+	vm *vm.VM
+	// The bytecode of the program, including the magic header.
+	program []byte
+	// The symbols to name functions with. This may be nil.
+	symbols *vm.SymbolTable
+	// The source map to show source lines with. This may be nil.
+	sourceMap *vm.SourceMap
+	// The disassembly of the program, which is shown for unmapped instructions.
+	disassembly []disasm.Line
+	// The name of the disassembly source.
+	disassemblyName string
+	// Whether to pause before the first instruction.
+	stopOnEntry bool
+	// The breakpoint addresses, per source.
+	breakpoints map[string][]uint64
+}
+
+func newServer(in io.Reader, out io.Writer) *server {
+	return &server{
+		mu:              sync.Mutex{},
+		wg:              sync.WaitGroup{},
+		cancel:          nil,
+		err:             nil,
+		in:              bufio.NewReader(in),
+		out:             out,
+		seq:             0,
+		pending:         nil,
+		isDone:          false,
+		vm:              nil,
+		program:         nil,
+		symbols:         nil,
+		sourceMap:       nil,
+		disassembly:     nil,
+		disassemblyName: "",
+		stopOnEntry:     false,
+		breakpoints:     map[string][]uint64{},
+	}
+}
+
+// serve handles requests until the client disconnects,
+// or until there are no more requests.
+// A program that is still running is canceled before serve returns.
+func (s *server) serve() error {
+	err := s.handleRequests()
+	s.stop()
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *server) handleRequests() error {
+	for !s.isDone {
+		req, err := readMessage(s.in)
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = s.handle(req)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *server) handle(req request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	resp := response{
+		Seq:        0,
+		Type:       "response",
+		RequestSeq: req.Seq,
+		Success:    true,
+		Command:    req.Command,
+		Message:    "",
+		Body:       nil,
+	}
+
+	h, hasHandler := handlers[req.Command]
+
+	switch {
+	case !hasHandler:
+		resp.Success = false
+		resp.Message = fmt.Sprintf("%s: %s", errUnknownCommand.Error(), req.Command)
+
+	case s.cancel != nil && !whileRunning[req.Command]:
+		resp.Success = false
+		resp.Message = errRunning.Error()
+
+	default:
+		body, err := h(s, req.Arguments)
+
+		if err != nil {
+			resp.Success = false
+			resp.Message = err.Error()
+		} else {
+			resp.Body = body
+		}
+	}
+
+	s.seq++
+	resp.Seq = s.seq
+
+	err := writeMessage(s.out, resp)
+
+	if err != nil {
+		return err
+	}
+
+	return s.flush()
+}
+
+// flush sends the events that have been queued.
+func (s *server) flush() error {
+	pending := s.pending
+	s.pending = nil
+
+	for _, e := range pending {
+		s.seq++
+		e.Seq = s.seq
+
+		err := writeMessage(s.out, e)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendEvent queues an event, to be sent after the current response,
+// or after the program that runs in the background has stopped.
+func (s *server) sendEvent(name string, body any) {
+	s.pending = append(s.pending, event{Seq: 0, Type: "event", Event: name, Body: body})
+}
+
+func decodeArguments(args json.RawMessage, v any) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(args, v)
+
+	if err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	return nil
+}
+
+func (s *server) ignore(_ json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func (s *server) initialize(_ json.RawMessage) (any, error) {
+	return map[string]any{
+		"supportsConfigurationDoneRequest": true,
+	}, nil
+}
+
+func (s *server) launch(rawArgs json.RawMessage) (any, error) {
+	var args launchArguments
+
+	err := decodeArguments(rawArgs, &args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := os.ReadFile(args.Program)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read program: %w", err)
+	}
+
+	magicHeader, err := hex.DecodeString(args.MagicHeader)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid magic header: %w", err)
+	}
+
+	if args.Symbols != "" {
+		s.symbols, err = readFile(args.Symbols, vm.ReadSymbolTable)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if args.SourceMap != "" {
+		s.sourceMap, err = readSourceMap(args.SourceMap)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.vm = vm.New(program, vm.WithMagicHeader(magicHeader))
+	s.program = program
+	s.disassembly = disasm.Listing(program, uint64(len(magicHeader)), s.symbols)
+	s.disassemblyName = filepath.Base(args.Program) + ".disasm"
+	s.stopOnEntry = args.StopOnEntry
+
+	s.sendEvent("initialized", nil)
+
+	return nil, nil
+}
+
+// readFile opens a file, and reads it with read.
+func readFile[T any](path string, read func(r io.Reader) (T, error)) (T, error) {
+	var result T
+
+	f, err := os.Open(path) // #nosec: G304
+
+	if err != nil {
+		return result, fmt.Errorf("could not open file: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return read(f)
+}
+
+// readSourceMap reads a source map, and resolves the source file paths in it
+// relative to the directory of the source map.
+func readSourceMap(path string) (*vm.SourceMap, error) {
+	sourceMap, err := readFile(path, vm.ReadSourceMap)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := sourceMap.Mappings()
+	dir := filepath.Dir(path)
+
+	for i, mapping := range mappings {
+		if !filepath.IsAbs(mapping.File) {
+			mappings[i].File = filepath.Join(dir, mapping.File)
+		}
+
+		mappings[i].File = filepath.Clean(mappings[i].File)
+	}
+
+	return vm.NewSourceMap(mappings), nil
+}
+
+func (s *server) configurationDone(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	if s.stopOnEntry {
+		s.sendStopped("entry", "")
+
+		return nil, nil
+	}
+
+	return s.cont(nil)
+}
+
+func (s *server) threads(_ json.RawMessage) (any, error) {
+	return map[string]any{
+		"threads": []map[string]any{{"id": threadID, "name": "main"}},
+	}, nil
+}
+
+func (s *server) disconnect(_ json.RawMessage) (any, error) {
+	s.interrupt()
+	s.isDone = true
+
+	return nil, nil
+}
+
+func (s *server) pause(_ json.RawMessage) (any, error) {
+	s.interrupt()
+
+	return nil, nil
+}
+
+// start runs the program in the background, so that requests such as pause
+// are handled while it runs.
+// The function that run returns reports why the program stopped.
+func (s *server) start(run func(ctx context.Context) func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		report := run(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		cancel()
+		s.cancel = nil
+
+		if s.isDone {
+			return
+		}
+
+		report()
+
+		if err := s.flush(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}()
+}
+
+// interrupt cancels the program if it is running in the background.
+func (s *server) interrupt() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// stop cancels the program if it is running in the background,
+// and waits for it to stop.
+func (s *server) stop() {
+	s.mu.Lock()
+	s.interrupt()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *server) isRunning() bool {
+	return s.vm != nil && s.vm.PC() < uint64(len(s.program))
+}
+
+func (s *server) cont(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	if !s.isRunning() {
+		s.sendExited()
+
+		return nil, nil
+	}
+
+	s.start(func(ctx context.Context) func() {
+		result, err := s.vm.RunContext(ctx)
+
+		return func() { s.report(result, err) }
+	})
+
+	return map[string]any{"allThreadsContinued": true}, nil
+}
+
+func (s *server) stepIn(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	if !s.isRunning() {
+		s.sendExited()
+
+		return nil, nil
+	}
+
+	_, err := s.vm.Step()
+
+	switch {
+	case err != nil:
+		s.sendStopped("exception", err.Error())
+
+	case !s.isRunning():
+		s.sendExited()
+
+	default:
+		s.sendStopped("step", "")
+	}
+
+	return nil, nil
+}
+
+func (s *server) next(_ json.RawMessage) (any, error) {
+	if !s.isRunning() {
+		return s.stepIn(nil)
+	}
+
+	pc := s.vm.PC()
+	opcode := vm.Opcode(s.program[pc])
+
+	if opcode != vm.OpcodeCallImmediate && opcode != vm.OpcodeCallRegister {
+		return s.stepIn(nil)
+	}
+
+	s.runUntilReturn(pc+vm.GetInstructionLen(opcode), s.vm.CallDepth())
+
+	return nil, nil
+}
+
+func (s *server) stepOut(_ json.RawMessage) (any, error) {
+	if !s.isRunning() {
+		return s.stepIn(nil)
+	}
+
+	frames := s.vm.CallFrames()
+
+	if len(frames) == 0 {
+		return s.cont(nil)
+	}
+
+	s.runUntilReturn(frames[len(frames)-1].ReturnAddress, uint64(len(frames)-1))
+
+	return nil, nil
+}
+
+// runUntilReturn runs the VM in the background until it reaches
+// returnAddress at the call depth depth, or until it stops for another reason.
+func (s *server) runUntilReturn(returnAddress uint64, depth uint64) {
+	s.start(func(ctx context.Context) func() {
+		return s.runUntilReturnContext(ctx, returnAddress, depth)
+	})
+}
+
+func (s *server) runUntilReturnContext(
+	ctx context.Context,
+	returnAddress uint64,
+	depth uint64,
+) func() {
+	if !slices.Contains(s.vm.Breakpoints(), returnAddress) &&
+		s.vm.SetBreakpoint(returnAddress) == nil {
+		defer s.vm.ClearBreakpoint(returnAddress)
+	}
+
+	for {
+		result, err := s.vm.RunContext(ctx)
+
+		isReturned := err == nil &&
+			result.Reason == vm.StopReasonPaused &&
+			result.Pause.Reason == vm.PauseReasonBreakpoint &&
+			result.Pause.PC == returnAddress
+
+		if isReturned && s.vm.CallDepth() > depth {
+			// A recursive call reached the return address, so keep going.
+			continue
+		}
+
+		if isReturned {
+			return func() { s.sendStopped("step", "") }
+		}
+
+		return func() { s.report(result, err) }
+	}
+}
+
+// report sends the events that describe why the VM stopped running.
+func (s *server) report(result vm.Result, err error) {
+	switch {
+	case errors.Is(err, vm.ErrCanceled):
+		s.sendStopped("pause", "")
+
+	case err != nil:
+		s.sendStopped("exception", err.Error())
+
+	case result.Reason == vm.StopReasonPaused &&
+		result.Pause.Reason == vm.PauseReasonWatchpoint:
+		s.sendStopped("data breakpoint", "")
+
+	case result.Reason == vm.StopReasonPaused:
+		s.sendStopped("breakpoint", "")
+
+	case result.Reason == vm.StopReasonHalted,
+		result.Reason == vm.StopReasonEndOfProgram:
+		s.sendExited()
+
+	default:
+		s.sendStopped("pause", result.Reason.String())
+	}
+}
+
+func (s *server) sendStopped(reason string, description string) {
+	body := map[string]any{
+		"reason":            reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	}
+
+	if description != "" {
+		body["description"] = description
+		body["text"] = description
+	}
+
+	s.sendEvent("stopped", body)
+}
+
+func (s *server) sendExited() {
+	s.sendEvent("exited", map[string]any{"exitCode": s.vm.ExitStatus()})
+	s.sendEvent("terminated", nil)
+}
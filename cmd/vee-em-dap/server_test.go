@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+var program = []byte{
+	0x00,
+	byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 11,
+	byte(vm.OpcodeHalt),
+	byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 7,
+	byte(vm.OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 3,
+	byte(vm.OpcodeStoreMemory), 0, 1,
+	byte(vm.OpcodeReturn),
+}
+
+// writeFiles writes the program, symbol table and source map into a
+// temporary directory, and returns the path of the directory.
+func writeFiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.bin": string(program),
+		"main.sym": "0x0001 main\n0x000b f\n",
+		"main.map": "0x0001 1 main.s\n0x000a 2 main.s\n0x000b 4 main.s\n" +
+			"0x0015 5 main.s\n0x001f 6 main.s\n0x0022 7 main.s\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	return dir
+}
+
+// session runs the server with the requests, and returns the messages that
+// it has sent.
+// Like an editor, it waits for the response to every request,
+// and waits for the program to stop before sending requests that need it.
+func session(t *testing.T, requests ...string) []map[string]any {
+	t.Helper()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	errs := make(chan error, 1)
+
+	go func() {
+		err := newServer(inR, outW).serve()
+		_ = outW.Close()
+		errs <- err
+	}()
+
+	var messages []map[string]any
+
+	r := bufio.NewReader(outR)
+	isRunning := false
+
+	// read reads messages until isLast returns true for one of them.
+	read := func(isLast func(msg map[string]any) bool) {
+		for {
+			msg, err := readTestMessage(r)
+
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			messages = append(messages, msg)
+
+			if msg["event"] == "stopped" || msg["event"] == "terminated" {
+				isRunning = false
+			}
+
+			if isLast(msg) {
+				return
+			}
+		}
+	}
+
+	for i, req := range requests {
+		command, args, _ := strings.Cut(req, " ")
+
+		if args == "" {
+			args = "{}"
+		}
+
+		if isRunning && command != "pause" && command != "threads" {
+			read(func(_ map[string]any) bool { return !isRunning })
+		}
+
+		body := fmt.Sprintf(`{"seq":%d,"type":"request","command":%q,"arguments":%s}`, i+1, command, args)
+
+		// The server may still be writing events, so the request is written
+		// in the background.
+		go func() { _, _ = fmt.Fprintf(inW, "Content-Length: %d\r\n\r\n%s", len(body), body) }()
+
+		read(func(msg map[string]any) bool {
+			return msg["type"] == "response" && msg["request_seq"] == float64(i+1)
+		})
+
+		switch command {
+		case "configurationDone", "continue", "next", "stepIn", "stepOut":
+			isRunning = true
+		}
+	}
+
+	_ = inW.Close()
+	read(func(_ map[string]any) bool { return false })
+
+	if err := <-errs; err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	return messages
+}
+
+// readTestMessage reads a message that the server has sent.
+func readTestMessage(r *bufio.Reader) (map[string]any, error) {
+	header, err := r.ReadString('\n')
+
+	if err != nil {
+		return nil, err
+	}
+
+	length, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+	_, _ = r.ReadString('\n')
+	body := make([]byte, length)
+	_, _ = io.ReadFull(r, body)
+
+	var msg map[string]any
+
+	return msg, json.Unmarshal(body, &msg)
+}
+
+// summarize returns a short description of every message.
+func summarize(messages []map[string]any) []string {
+	summary := make([]string, len(messages))
+
+	for i, msg := range messages {
+		switch msg["type"] {
+		case "response":
+			summary[i] = fmt.Sprintf("response %s %v", msg["command"], msg["success"])
+
+		case "event":
+			summary[i] = fmt.Sprintf("event %s", msg["event"])
+
+			if body, isMap := msg["body"].(map[string]any); isMap && body["reason"] != nil {
+				summary[i] += fmt.Sprintf(" %s", body["reason"])
+			}
+		}
+	}
+
+	return summary
+}
+
+func findResponse(t *testing.T, messages []map[string]any, command string, n int) map[string]any {
+	t.Helper()
+
+	for _, msg := range messages {
+		if msg["type"] != "response" || msg["command"] != command {
+			continue
+		}
+
+		if n == 0 {
+			body, _ := msg["body"].(map[string]any)
+
+			return body
+		}
+
+		n--
+	}
+
+	t.Fatalf("expected a response to %s", command)
+
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t)
+	source := filepath.Join(dir, "main.s")
+
+	messages := session(
+		t,
+		"initialize",
+		fmt.Sprintf(
+			`launch {"program":%q,"magicHeader":"00","symbols":%q,"sourceMap":%q,"stopOnEntry":true}`,
+			filepath.Join(dir, "main.bin"),
+			filepath.Join(dir, "main.sym"),
+			filepath.Join(dir, "main.map"),
+		),
+		fmt.Sprintf(`setBreakpoints {"source":{"path":%q},"breakpoints":[{"line":6},{"line":3}]}`, source),
+		"configurationDone",
+		"threads",
+		"continue",
+		"stackTrace",
+		"scopes",
+		`variables {"variablesReference":1}`,
+		`variables {"variablesReference":4}`,
+		`variables {"variablesReference":5}`,
+		"next",
+		"stepOut",
+		"stackTrace",
+		"continue",
+		"disconnect",
+	)
+
+	expected := []string{
+		"response initialize true",
+		"response launch true",
+		"event initialized",
+		"response setBreakpoints true",
+		"response configurationDone true",
+		"event stopped entry",
+		"response threads true",
+		"response continue true",
+		"event stopped breakpoint",
+		"response stackTrace true",
+		"response scopes true",
+		"response variables true",
+		"response variables true",
+		"response variables true",
+		"response next true",
+		"event stopped step",
+		"response stepOut true",
+		"event stopped step",
+		"response stackTrace true",
+		"response continue true",
+		"event exited",
+		"event terminated",
+		"response disconnect true",
+	}
+
+	if got := summarize(messages); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected messages:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	breakpoints, _ := json.Marshal(findResponse(t, messages, "setBreakpoints", 0)["breakpoints"])
+
+	if string(breakpoints) != `[{"line":6,"verified":true},{"line":3,"message":"no instruction on this line","verified":false}]` {
+		t.Fatalf("unexpected breakpoints: %s", breakpoints)
+	}
+
+	frames, _ := json.Marshal(findResponse(t, messages, "stackTrace", 0)["stackFrames"])
+	expectedFrames := fmt.Sprintf(
+		`[{"column":1,"id":0,"instructionPointerReference":"0x001f","line":6,"name":"f","source":{"name":"main.s","path":%q}},`+
+			`{"column":1,"id":1,"instructionPointerReference":"0x0001","line":1,"name":"main","source":{"name":"main.s","path":%q}}]`,
+		source,
+		source,
+	)
+
+	if string(frames) != expectedFrames {
+		t.Fatalf("expected stack frames to be %s, got %s", expectedFrames, frames)
+	}
+
+	registers, _ := json.Marshal(findResponse(t, messages, "variables", 0)["variables"])
+
+	if !strings.Contains(string(registers), `{"name":"r0","value":"7","variablesReference":0}`) {
+		t.Fatalf("expected r0 to be 7, got %s", registers)
+	}
+
+	callFrames, _ := json.Marshal(findResponse(t, messages, "variables", 1)["variables"])
+
+	if string(callFrames) != `[{"name":"#1","value":"call 0x000b from 0x0001, return to 0x000a","variablesReference":0}]` {
+		t.Fatalf("unexpected call frames: %s", callFrames)
+	}
+
+	heap, _ := json.Marshal(findResponse(t, messages, "variables", 2)["variables"])
+
+	if string(heap) != `[]` {
+		t.Fatalf("expected the heap to be empty before the store, got %s", heap)
+	}
+
+	frames, _ = json.Marshal(findResponse(t, messages, "stackTrace", 1)["stackFrames"])
+
+	if !strings.Contains(string(frames), `"line":2,"name":"main"`) || strings.Contains(string(frames), `"id":1`) {
+		t.Fatalf("expected to be back in main at line 2, got %s", frames)
+	}
+}
+
+func TestServerDisassembly(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t)
+
+	messages := session(
+		t,
+		"initialize",
+		fmt.Sprintf(`launch {"program":%q,"magicHeader":"00"}`, filepath.Join(dir, "main.bin")),
+		`setBreakpoints {"source":{"sourceReference":1},"breakpoints":[{"line":3}]}`,
+		"configurationDone",
+		"stackTrace",
+		`source {"sourceReference":1}`,
+		"stepIn",
+		"stepIn",
+		"stepIn",
+		`variables {"variablesReference":5}`,
+		`variables {"variablesReference":1000}`,
+		"stepIn",
+		"stepIn",
+		"stepIn",
+		"unknown",
+	)
+
+	expected := []string{
+		"response initialize true",
+		"response launch true",
+		"event initialized",
+		"response setBreakpoints true",
+		"response configurationDone true",
+		"event stopped breakpoint",
+		"response stackTrace true",
+		"response source true",
+		"response stepIn true",
+		"event stopped step",
+		"response stepIn true",
+		"event stopped step",
+		"response stepIn true",
+		"event stopped step",
+		"response variables true",
+		"response variables true",
+		"response stepIn true",
+		"event stopped step",
+		"response stepIn true",
+		"event exited",
+		"event terminated",
+		"response stepIn true",
+		"event exited",
+		"event terminated",
+		"response unknown false",
+	}
+
+	if got := summarize(messages); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected messages:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	frames, _ := json.Marshal(findResponse(t, messages, "stackTrace", 0)["stackFrames"])
+
+	if !strings.Contains(string(frames), `"line":3,"name":"sub_000b","source":{"name":"main.bin.disasm","sourceReference":1}`) {
+		t.Fatalf("expected to be in the disassembly at line 3, got %s", frames)
+	}
+
+	content := findResponse(t, messages, "source", 0)["content"]
+	expectedContent := "0x0001     CallImmediate 0x000b\n0x000a     Halt\n0x000b     LoadImmediate r0, 7\n"
+
+	if content, _ := content.(string); !strings.HasPrefix(content, expectedContent) {
+		t.Fatalf("expected the disassembly to start with %q, got %q", expectedContent, content)
+	}
+
+	heap, _ := json.Marshal(findResponse(t, messages, "variables", 0)["variables"])
+
+	if string(heap) != `[{"name":"0x0000","value":"[0 0 0 7 0 0 0 0 0 0 0 0 0 0 0 0]","variablesReference":1000}]` {
+		t.Fatalf("unexpected heap windows: %s", heap)
+	}
+
+	window, _ := json.Marshal(findResponse(t, messages, "variables", 1)["variables"])
+
+	if !strings.Contains(string(window), `{"name":"[3]","value":"7","variablesReference":0}`) {
+		t.Fatalf("expected heap address 3 to be 7, got %s", window)
+	}
+}
+
+func TestServerPause(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "loop.bin")
+	loop := []byte{byte(vm.OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0, 0}
+
+	if err := os.WriteFile(path, loop, 0o600); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	messages := session(
+		t,
+		"initialize",
+		fmt.Sprintf(`launch {"program":%q}`, path),
+		"configurationDone",
+		"threads",
+		"pause",
+		"continue",
+		"pause",
+		"disconnect",
+	)
+
+	expected := []string{
+		"response initialize true",
+		"response launch true",
+		"event initialized",
+		"response configurationDone true",
+		"response threads true",
+		"response pause true",
+		"event stopped pause",
+		"response continue true",
+		"response pause true",
+		"event stopped pause",
+		"response disconnect true",
+	}
+
+	if got := summarize(messages); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected messages:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
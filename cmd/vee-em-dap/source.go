@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// disassemblySourceReference is the source reference of the disassembly,
+// which the client fetches with a source request.
+const disassemblySourceReference = 1
+
+// disassemblySourceKey is the key of the disassembly in server.breakpoints.
+const disassemblySourceKey = "disassembly"
+
+// dapSource defines a source, which is either a file or the disassembly.
+type dapSource struct {
+	Name            string `json:"name,omitempty"`
+	Path            string `json:"path,omitempty"`
+	SourceReference int    `json:"sourceReference,omitempty"`
+}
+
+type dapBreakpoint struct {
+	Verified bool   `json:"verified"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type setBreakpointsArguments struct {
+	Source      dapSource `json:"source"`
+	Breakpoints []struct {
+		Line int `json:"line"`
+	} `json:"breakpoints"`
+}
+
+type stackFrame struct {
+	ID                          int        `json:"id"`
+	Name                        string     `json:"name"`
+	Source                      *dapSource `json:"source,omitempty"`
+	Line                        int        `json:"line"`
+	Column                      int        `json:"column"`
+	InstructionPointerReference string     `json:"instructionPointerReference"`
+}
+
+// location returns the source and line of the instruction at addr.
+// Instructions without a source mapping are shown in the disassembly.
+func (s *server) location(addr uint64) (*dapSource, int) {
+	mapping, hasMapping := s.sourceMap.Lookup(addr)
+
+	if hasMapping {
+		return &dapSource{
+			Name:            filepath.Base(mapping.File),
+			Path:            mapping.File,
+			SourceReference: 0,
+		}, int(mapping.Line) // #nosec: G115
+	}
+
+	for i, line := range s.disassembly {
+		if line.IsInstruction && line.Address == addr {
+			return s.disassemblySource(), i + 1
+		}
+	}
+
+	return nil, 0
+}
+
+func (s *server) disassemblySource() *dapSource {
+	return &dapSource{
+		Name:            s.disassemblyName,
+		Path:            "",
+		SourceReference: disassemblySourceReference,
+	}
+}
+
+// addresses returns the addresses of the instructions on a line of a source.
+func (s *server) addresses(source dapSource, line int) []uint64 {
+	if source.SourceReference == disassemblySourceReference {
+		if line < 1 || line > len(s.disassembly) || !s.disassembly[line-1].IsInstruction {
+			return nil
+		}
+
+		return []uint64{s.disassembly[line-1].Address}
+	}
+
+	// #nosec: G115
+	return s.sourceMap.Addresses(filepath.Clean(source.Path), uint64(line))
+}
+
+func (s *server) setBreakpoints(rawArgs json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	var args setBreakpointsArguments
+
+	err := decodeArguments(rawArgs, &args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := filepath.Clean(args.Source.Path)
+
+	if args.Source.SourceReference == disassemblySourceReference {
+		key = disassemblySourceKey
+	}
+
+	for _, addr := range s.breakpoints[key] {
+		s.vm.ClearBreakpoint(addr)
+	}
+
+	s.breakpoints[key] = nil
+	breakpoints := make([]dapBreakpoint, len(args.Breakpoints))
+
+	for i, bp := range args.Breakpoints {
+		breakpoints[i] = dapBreakpoint{Verified: false, Line: bp.Line, Message: ""}
+		addrs := s.addresses(args.Source, bp.Line)
+
+		if len(addrs) == 0 {
+			breakpoints[i].Message = "no instruction on this line"
+
+			continue
+		}
+
+		err = s.vm.SetBreakpoint(addrs[0])
+
+		if err != nil {
+			breakpoints[i].Message = err.Error()
+
+			continue
+		}
+
+		s.breakpoints[key] = append(s.breakpoints[key], addrs[0])
+		breakpoints[i].Verified = true
+	}
+
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+func (s *server) source(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	var sb strings.Builder
+
+	for _, line := range s.disassembly {
+		if line.IsInstruction {
+			fmt.Fprintf(&sb, "0x%04x %s\n", line.Address, line.Text)
+		} else {
+			fmt.Fprintf(&sb, "%s\n", line.Text)
+		}
+	}
+
+	return map[string]any{
+		"content":  sb.String(),
+		"mimeType": "text/x-asm",
+	}, nil
+}
+
+func (s *server) stackTrace(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	frames := []stackFrame{}
+
+	if s.isRunning() {
+		callFrames := s.vm.CallFrames()
+		frames = append(frames, s.stackFrame(0, s.vm.PC(), callFrames))
+
+		for i := len(callFrames) - 1; i >= 0; i-- {
+			frames = append(
+				frames,
+				s.stackFrame(len(callFrames)-i, callFrames[i].CallSite, callFrames[:i]),
+			)
+		}
+	}
+
+	return map[string]any{
+		"stackFrames": frames,
+		"totalFrames": len(frames),
+	}, nil
+}
+
+// stackFrame creates a stack frame at addr, in the function that has been
+// called by the innermost of the call frames.
+func (s *server) stackFrame(id int, addr uint64, callFrames []vm.CallFrame) stackFrame {
+	source, line := s.location(addr)
+
+	return stackFrame{
+		ID:                          id,
+		Name:                        s.functionName(addr, callFrames),
+		Source:                      source,
+		Line:                        line,
+		Column:                      1,
+		InstructionPointerReference: fmt.Sprintf("0x%04x", addr),
+	}
+}
+
+// functionName returns the name of the function that contains addr.
+func (s *server) functionName(addr uint64, callFrames []vm.CallFrame) string {
+	symbol, hasSymbol := s.symbols.Lookup(addr)
+
+	if hasSymbol {
+		return symbol.Name
+	}
+
+	if len(callFrames) == 0 {
+		return "main"
+	}
+
+	return fmt.Sprintf("sub_%04x", callFrames[len(callFrames)-1].Target)
+}
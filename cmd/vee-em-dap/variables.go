@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// The variables references of the scopes.
+const (
+	registersReference = iota + 1
+	flagsReference
+	stackReference
+	callFramesReference
+	heapReference
+
+	// heapWindowReference is the variables reference of the first heap window.
+	// The reference of every next window is one higher.
+	heapWindowReference = 1000
+)
+
+// heapWindowSize is the number of heap values in a heap window.
+const heapWindowSize = 16
+
+type scope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type variablesArguments struct {
+	VariablesReference int `json:"variablesReference"`
+}
+
+func (s *server) scopes(_ json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	return map[string]any{
+		"scopes": []scope{
+			{Name: "Registers", VariablesReference: registersReference, Expensive: false},
+			{Name: "Flags", VariablesReference: flagsReference, Expensive: false},
+			{Name: "Stack", VariablesReference: stackReference, Expensive: false},
+			{Name: "Call Frames", VariablesReference: callFramesReference, Expensive: false},
+			{Name: "Heap", VariablesReference: heapReference, Expensive: true},
+		},
+	}, nil
+}
+
+func (s *server) variables(rawArgs json.RawMessage) (any, error) {
+	if s.vm == nil {
+		return nil, errNotLaunched
+	}
+
+	var args variablesArguments
+
+	err := decodeArguments(rawArgs, &args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var variables []variable
+
+	switch args.VariablesReference {
+	case registersReference:
+		variables = s.registerVariables()
+
+	case flagsReference:
+		flags := s.vm.Flags()
+		variables = []variable{
+			newVariable("zero", fmt.Sprintf("%t", flags.IsZero)),
+			newVariable("negative", fmt.Sprintf("%t", flags.IsNegative)),
+		}
+
+	case stackReference:
+		values := s.vm.StackView()
+
+		for i, value := range slices.Backward(values) {
+			variables = append(variables, newVariable(fmt.Sprintf("[%d]", i), fmt.Sprintf("%d", value)))
+		}
+
+	case callFramesReference:
+		variables = s.callFrameVariables()
+
+	case heapReference:
+		variables, err = s.heapWindows()
+
+	default:
+		variables, err = s.heapWindow(args.VariablesReference - heapWindowReference)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if variables == nil {
+		variables = []variable{}
+	}
+
+	return map[string]any{"variables": variables}, nil
+}
+
+func newVariable(name string, value string) variable {
+	return variable{Name: name, Value: value, VariablesReference: 0}
+}
+
+func (s *server) registerVariables() []variable {
+	variables := []variable{
+		newVariable("pc", fmt.Sprintf("0x%04x", s.vm.PC())),
+		newVariable("sp", fmt.Sprintf("%d", s.vm.SP())),
+	}
+
+	for i, value := range s.vm.Registers() {
+		variables = append(variables, newVariable(fmt.Sprintf("r%d", i), fmt.Sprintf("%d", value)))
+	}
+
+	return variables
+}
+
+func (s *server) callFrameVariables() []variable {
+	frames := s.vm.CallFrames()
+	variables := make([]variable, 0, len(frames))
+
+	for i, frame := range slices.Backward(frames) {
+		variables = append(variables, newVariable(
+			fmt.Sprintf("#%d", len(frames)-i),
+			fmt.Sprintf(
+				"call 0x%04x from 0x%04x, return to 0x%04x",
+				frame.Target,
+				frame.CallSite,
+				frame.ReturnAddress,
+			),
+		))
+	}
+
+	return variables
+}
+
+// heapWindows returns a variable for every window of the heap that contains
+// a value that is not zero.
+func (s *server) heapWindows() ([]variable, error) {
+	heap, err := s.vm.ReadHeap(0, s.vm.HeapSize())
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read heap: %w", err)
+	}
+
+	var variables []variable
+
+	for start := 0; start < len(heap); start += heapWindowSize {
+		window := heap[start:min(start+heapWindowSize, len(heap))]
+
+		if !slices.ContainsFunc(window, func(value int64) bool { return value != 0 }) {
+			continue
+		}
+
+		variables = append(variables, variable{
+			Name:               fmt.Sprintf("0x%04x", start),
+			Value:              fmt.Sprintf("%v", window),
+			VariablesReference: heapWindowReference + start/heapWindowSize,
+		})
+	}
+
+	return variables, nil
+}
+
+// heapWindow returns a variable for every value in a window of the heap.
+func (s *server) heapWindow(window int) ([]variable, error) {
+	if window < 0 {
+		return nil, nil
+	}
+
+	start := uint64(window) * heapWindowSize
+	heapSize := s.vm.HeapSize()
+
+	if start >= heapSize {
+		return nil, nil
+	}
+
+	values, err := s.vm.ReadHeap(start, min(heapWindowSize, heapSize-start))
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read heap: %w", err)
+	}
+
+	variables := make([]variable, len(values))
+
+	for i, value := range values {
+		variables[i] = newVariable(fmt.Sprintf("[%d]", start+uint64(i)), fmt.Sprintf("%d", value))
+	}
+
+	return variables, nil
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+// disasmContext is the number of instructions that disasm shows
+// before and after the program counter.
+const disasmContext = 5
+
+var (
+	errQuit          = errors.New("quit")
+	errUsage         = errors.New("invalid arguments, see \"help\"")
+	errUnknownSymbol = errors.New("unknown symbol")
+	errNotRunning    = errors.New("the program is not running")
+)
+
+// command defines a debugger command.
+type command struct {
+	// The names that the command can be invoked with.
+	names []string
+	// The arguments of the command, as shown by help.
+	usage string
+	// A short description of the command, as shown by help.
+	help string
+	// The function that runs the command.
+	run func(d *debugger, args []string) error
+}
+
+// getCommands returns all debugger commands.
+func getCommands() []command {
+	return []command{
+		{
+			names: []string{"step", "s"},
+			usage: "[n]",
+			help:  "execute n instructions",
+			run:   (*debugger).step,
+		},
+		{
+			names: []string{"next", "n"},
+			usage: "",
+			help:  "execute one instruction, stepping over calls",
+			run:   (*debugger).next,
+		},
+		{
+			names: []string{"continue", "c"},
+			usage: "",
+			help:  "run until a breakpoint, watchpoint or the end",
+			run:   (*debugger).cont,
+		},
+		{
+			names: []string{"break", "b"},
+			usage: "<addr|label>",
+			help:  "set a breakpoint",
+			run:   (*debugger).breakpoint,
+		},
+		{
+			names: []string{"clear"},
+			usage: "<addr|label>",
+			help:  "remove a breakpoint",
+			run:   (*debugger).clear,
+		},
+		{
+			names: []string{"watch", "w"},
+			usage: "<heap addr> [r|w|rw]",
+			help:  "pause when a heap address is accessed",
+			run:   (*debugger).watch,
+		},
+		{
+			names: []string{"regs", "r"},
+			usage: "",
+			help:  "show the registers and flags",
+			run:   (*debugger).regs,
+		},
+		{
+			names: []string{"stack"},
+			usage: "",
+			help:  "show the stack, top first",
+			run:   (*debugger).stack,
+		},
+		{
+			names: []string{"heap"},
+			usage: "<addr> <n>",
+			help:  "show n values of the heap",
+			run:   (*debugger).heap,
+		},
+		{
+			names: []string{"disasm", "d"},
+			usage: "",
+			help:  "disassemble around the program counter",
+			run:   (*debugger).disasm,
+		},
+		{
+			names: []string{"backtrace", "bt"},
+			usage: "",
+			help:  "show the call stack, innermost first",
+			run:   (*debugger).backtrace,
+		},
+		{
+			names: []string{"reset"},
+			usage: "",
+			help:  "restart the program",
+			run:   (*debugger).reset,
+		},
+		{
+			names: []string{"help", "h"},
+			usage: "",
+			help:  "show this help",
+			run:   (*debugger).help,
+		},
+		{
+			names: []string{"quit", "q"},
+			usage: "",
+			help:  "exit the debugger",
+			run:   (*debugger).quit,
+		},
+	}
+}
+
+// debugger defines the state of an interactive debugging session.
+type debugger struct {
+	// The VM that is being debugged.
+	vm *vm.VM
+	// The bytecode of the program, including the magic header.
+	program []byte
+	// The length of the magic header.
+	headerLen uint64
+	// The symbols to name addresses with. This may be nil.
+	symbols *vm.SymbolTable
+	// The disassembly of the program.
+	lines []disasm.Line
+	// Where the output is written to.
+	out io.Writer
+	// Creates the context that continue and next run the program with,
+	// so that they can be interrupted.
+	newContext func() (context.Context, context.CancelFunc)
+}
+
+func newDebugger(
+	program []byte,
+	magicHeader []byte,
+	symbols *vm.SymbolTable,
+	out io.Writer,
+) *debugger {
+	headerLen := uint64(len(magicHeader))
+
+	return &debugger{
+		vm:         vm.New(program, vm.WithMagicHeader(magicHeader)),
+		program:    program,
+		headerLen:  headerLen,
+		symbols:    symbols,
+		lines:      disasm.Listing(program, headerLen, symbols),
+		out:        out,
+		newContext: interruptContext,
+	}
+}
+
+// interruptContext returns a context that is canceled when the user
+// presses Ctrl-C, so that a running program pauses instead of the debugger
+// being killed.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// repl reads commands from in until it is exhausted, or until "quit".
+// An empty line repeats the previous command.
+func (d *debugger) repl(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	previous := ""
+
+	d.showCurrent()
+
+	for {
+		fmt.Fprint(d.out, "(vee-em) ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(d.out)
+
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			line = previous
+		}
+
+		previous = line
+		err := d.exec(line)
+
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+
+		if err != nil {
+			fmt.Fprintf(d.out, "error: %s\n", err.Error())
+		}
+	}
+
+	err := scanner.Err()
+
+	if err != nil {
+		return fmt.Errorf("could not read command: %w", err)
+	}
+
+	return nil
+}
+
+func (d *debugger) exec(line string) error {
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	for _, cmd := range getCommands() {
+		if slices.Contains(cmd.names, fields[0]) {
+			return cmd.run(d, fields[1:])
+		}
+	}
+
+	return fmt.Errorf("unknown command %q, see \"help\"", fields[0])
+}
+
+func (d *debugger) isRunning() bool {
+	return d.vm.PC() < uint64(len(d.program))
+}
+
+func (d *debugger) step(args []string) error {
+	n := uint64(1)
+
+	if len(args) > 1 {
+		return errUsage
+	}
+
+	if len(args) == 1 {
+		var err error
+
+		n, err = strconv.ParseUint(args[0], 0, 64)
+
+		if err != nil {
+			return errUsage
+		}
+	}
+
+	if !d.isRunning() {
+		return errNotRunning
+	}
+
+	for range n {
+		if !d.isRunning() {
+			break
+		}
+
+		_, err := d.vm.Step()
+
+		if err != nil {
+			fmt.Fprintf(d.out, "stopped: %s\n", err.Error())
+
+			return nil
+		}
+	}
+
+	d.showCurrent()
+
+	return nil
+}
+
+func (d *debugger) next(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	if !d.isRunning() {
+		return errNotRunning
+	}
+
+	pc := d.vm.PC()
+	opcode := vm.Opcode(d.program[pc])
+
+	if opcode != vm.OpcodeCallImmediate && opcode != vm.OpcodeCallRegister {
+		return d.step(nil)
+	}
+
+	returnAddress := pc + vm.GetInstructionLen(opcode)
+	depth := d.vm.CallDepth()
+
+	ctx, stop := d.newContext()
+	defer stop()
+
+	if !slices.Contains(d.vm.Breakpoints(), returnAddress) &&
+		d.vm.SetBreakpoint(returnAddress) == nil {
+		defer d.vm.ClearBreakpoint(returnAddress)
+	}
+
+	for {
+		result, err := d.vm.RunContext(ctx)
+
+		isReturned := err == nil &&
+			result.Reason == vm.StopReasonPaused &&
+			result.Pause.Reason == vm.PauseReasonBreakpoint &&
+			result.Pause.PC == returnAddress
+
+		if isReturned && d.vm.CallDepth() > depth {
+			// A recursive call reached the return address, so keep going.
+			continue
+		}
+
+		if isReturned {
+			d.showCurrent()
+
+			return nil
+		}
+
+		d.report(result, err)
+
+		return nil
+	}
+}
+
+func (d *debugger) cont(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	if !d.isRunning() {
+		return errNotRunning
+	}
+
+	ctx, stop := d.newContext()
+	defer stop()
+
+	result, err := d.vm.RunContext(ctx)
+	d.report(result, err)
+
+	return nil
+}
+
+func (d *debugger) breakpoint(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	addr, err := d.parseAddress(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	err = d.vm.SetBreakpoint(addr)
+
+	if err != nil {
+		return fmt.Errorf("could not set breakpoint: %w", err)
+	}
+
+	fmt.Fprintf(d.out, "breakpoint at %s\n", d.formatAddress(addr))
+
+	return nil
+}
+
+func (d *debugger) clear(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	addr, err := d.parseAddress(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	d.vm.ClearBreakpoint(addr)
+
+	return nil
+}
+
+func (d *debugger) watch(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errUsage
+	}
+
+	addr, err := strconv.ParseUint(args[0], 0, 64)
+
+	if err != nil {
+		return errUsage
+	}
+
+	kind := vm.WatchReadWrite
+
+	if len(args) == 2 {
+		kinds := map[string]vm.WatchKind{
+			"r":  vm.WatchRead,
+			"w":  vm.WatchWrite,
+			"rw": vm.WatchReadWrite,
+		}
+
+		var isKind bool
+
+		kind, isKind = kinds[args[1]]
+
+		if !isKind {
+			return errUsage
+		}
+	}
+
+	err = d.vm.SetWatchpoint(addr, kind)
+
+	if err != nil {
+		return fmt.Errorf("could not set watchpoint: %w", err)
+	}
+
+	fmt.Fprintf(d.out, "watchpoint at heap address %d\n", addr)
+
+	return nil
+}
+
+func (d *debugger) regs(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	for i, value := range d.vm.Registers() {
+		sep := "  "
+
+		if i%4 == 3 {
+			sep = "\n"
+		}
+
+		fmt.Fprintf(d.out, "r%-2d %-20d%s", i, value, sep)
+	}
+
+	flags := d.vm.Flags()
+
+	fmt.Fprintf(
+		d.out,
+		"pc  %s  sp %d  zero %t  negative %t\n",
+		d.formatAddress(d.vm.PC()),
+		d.vm.SP(),
+		flags.IsZero,
+		flags.IsNegative,
+	)
+
+	return nil
+}
+
+func (d *debugger) stack(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	values := d.vm.StackView()
+
+	if len(values) == 0 {
+		fmt.Fprintln(d.out, "the stack is empty")
+
+		return nil
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		fmt.Fprintf(d.out, "%4d: %d\n", i, values[i])
+	}
+
+	return nil
+}
+
+func (d *debugger) heap(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	addr, err := strconv.ParseUint(args[0], 0, 64)
+
+	if err != nil {
+		return errUsage
+	}
+
+	n, err := strconv.ParseUint(args[1], 0, 64)
+
+	if err != nil {
+		return errUsage
+	}
+
+	values, err := d.vm.ReadHeap(addr, n)
+
+	if err != nil {
+		return fmt.Errorf("could not read heap: %w", err)
+	}
+
+	for i, value := range values {
+		fmt.Fprintf(d.out, "%6d: %d\n", addr+uint64(i), value)
+	}
+
+	return nil
+}
+
+func (d *debugger) disasm(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	pc := d.vm.PC()
+	current := slices.IndexFunc(d.lines, func(line disasm.Line) bool {
+		return line.IsInstruction && line.Address >= pc
+	})
+
+	if current == -1 {
+		current = len(d.lines)
+	}
+
+	start := max(current-disasmContext, 0)
+	end := min(current+disasmContext+1, len(d.lines))
+	breakpoints := d.vm.Breakpoints()
+
+	for _, line := range d.lines[start:end] {
+		marker := "  "
+
+		switch {
+		case !line.IsInstruction:
+			fmt.Fprintf(d.out, "%s\n", line.Text)
+
+			continue
+
+		case line.Address == pc:
+			marker = "=>"
+
+		case slices.Contains(breakpoints, line.Address):
+			marker = " *"
+		}
+
+		fmt.Fprintf(d.out, "%s 0x%04x %s\n", marker, line.Address, line.Text)
+	}
+
+	return nil
+}
+
+func (d *debugger) backtrace(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	frames := d.vm.CallFrames()
+	fmt.Fprintf(d.out, "#0 %s\n", d.formatAddress(d.vm.PC()))
+
+	for i := len(frames) - 1; i >= 0; i-- {
+		fmt.Fprintf(
+			d.out,
+			"#%d %s\n",
+			len(frames)-i,
+			d.formatAddress(frames[i].CallSite),
+		)
+	}
+
+	return nil
+}
+
+func (d *debugger) reset(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	breakpoints := d.vm.Breakpoints()
+	watchpoints := d.vm.Watchpoints()
+
+	d.vm.Reset(false)
+
+	// The breakpoints and watchpoints were set by the user,
+	// so they are kept when restarting the program.
+	for _, addr := range breakpoints {
+		_ = d.vm.SetBreakpoint(addr)
+	}
+
+	for _, watchpoint := range watchpoints {
+		_ = d.vm.SetWatchpoint(watchpoint.Address, watchpoint.Kind)
+	}
+
+	d.showCurrent()
+
+	return nil
+}
+
+func (d *debugger) help(_ []string) error {
+	for _, cmd := range getCommands() {
+		usage := strings.Join(cmd.names, ", ")
+
+		if cmd.usage != "" {
+			usage += " " + cmd.usage
+		}
+
+		fmt.Fprintf(d.out, "%-32s %s\n", usage, cmd.help)
+	}
+
+	return nil
+}
+
+func (d *debugger) quit(_ []string) error {
+	return errQuit
+}
+
+// parseAddress parses an address, which is either a number or a label from
+// the symbol table.
+func (d *debugger) parseAddress(s string) (uint64, error) {
+	addr, err := strconv.ParseUint(s, 0, 64)
+
+	if err == nil {
+		return addr, nil
+	}
+
+	addr, hasSymbol := d.symbols.Address(s)
+
+	if !hasSymbol {
+		return 0, fmt.Errorf("%w: %s", errUnknownSymbol, s)
+	}
+
+	return addr, nil
+}
+
+// formatAddress formats an address, along with the symbol that contains it.
+func (d *debugger) formatAddress(addr uint64) string {
+	symbol, hasSymbol := d.symbols.Lookup(addr)
+
+	if !hasSymbol {
+		return fmt.Sprintf("0x%04x", addr)
+	}
+
+	if symbol.Address == addr {
+		return fmt.Sprintf("0x%04x <%s>", addr, symbol.Name)
+	}
+
+	return fmt.Sprintf("0x%04x <%s+%d>", addr, symbol.Name, addr-symbol.Address)
+}
+
+// report describes why the VM stopped running.
+func (d *debugger) report(result vm.Result, err error) {
+	if errors.Is(err, vm.ErrCanceled) {
+		fmt.Fprintln(d.out, "paused")
+		d.showCurrent()
+
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(d.out, "stopped: %s\n", err.Error())
+
+		return
+	}
+
+	switch result.Reason {
+	case vm.StopReasonPaused:
+		pause := result.Pause
+
+		if pause.Reason == vm.PauseReasonWatchpoint {
+			access := "read"
+
+			if pause.Access == vm.WatchWrite {
+				access = "write"
+			}
+
+			fmt.Fprintf(
+				d.out,
+				"watchpoint: %s of heap address %d at %s\n",
+				access,
+				pause.Address,
+				d.formatAddress(pause.PC),
+			)
+		} else {
+			fmt.Fprintf(d.out, "breakpoint at %s\n", d.formatAddress(pause.PC))
+		}
+
+		d.showCurrent()
+
+	case vm.StopReasonHalted:
+		fmt.Fprintf(d.out, "halted with exit status %d\n", result.ExitStatus)
+
+	case vm.StopReasonEndOfProgram,
+		vm.StopReasonOutOfFuel,
+		vm.StopReasonCanceled,
+		vm.StopReasonFaulted:
+		fmt.Fprintf(d.out, "stopped: %s\n", result.Reason)
+	}
+}
+
+// showCurrent shows the instruction that will be executed next.
+func (d *debugger) showCurrent() {
+	pc := d.vm.PC()
+
+	if !d.isRunning() {
+		fmt.Fprintf(d.out, "the program has stopped with exit status %d\n", d.vm.ExitStatus())
+
+		return
+	}
+
+	for _, line := range d.lines {
+		if line.IsInstruction && line.Address == pc {
+			fmt.Fprintf(d.out, "=> %s %s\n", d.formatAddress(pc), strings.TrimSpace(line.Text))
+
+			return
+		}
+	}
+
+	fmt.Fprintf(d.out, "=> %s\n", d.formatAddress(pc))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+var program = []byte{
+	0x00,
+	byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 11,
+	byte(vm.OpcodeHalt),
+	byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 7,
+	byte(vm.OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 3,
+	byte(vm.OpcodeStoreMemory), 0, 1,
+	byte(vm.OpcodeReturn),
+}
+
+func TestDebugger(t *testing.T) {
+	t.Parallel()
+
+	symbols := vm.NewSymbolTable([]vm.Symbol{
+		{Name: "main", Address: 1},
+		{Name: "f", Address: 11},
+	})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:  "breakpoints and watchpoints",
+			input: "break f\nc\nbt\nwatch 3 w\nc\nheap 3 1\ns\nregs\nc\n",
+			expected: []string{
+				"=> 0x0001 <main> CallImmediate 0x000b\n",
+				"(vee-em) breakpoint at 0x000b <f>\n",
+				"(vee-em) breakpoint at 0x000b <f>\n=> 0x000b <f> LoadImmediate r0, 7\n",
+				"(vee-em) #0 0x000b <f>\n#1 0x0001 <main>\n",
+				"(vee-em) watchpoint at heap address 3\n",
+				"(vee-em) watchpoint: write of heap address 3 at 0x001f <f+20>\n=> 0x0022 <f+23> Return\n",
+				"(vee-em)      3: 7\n",
+				"(vee-em) => 0x000a <main+9> Halt\n",
+				"r0  7                     r1  3                     r2  0",
+				"pc  0x000a <main+9>  sp 0  zero false  negative false\n",
+				"(vee-em) halted with exit status 0\n",
+			},
+		},
+		{
+			name:  "next",
+			input: "n\n\nn\n",
+			expected: []string{
+				"(vee-em) => 0x000a <main+9> Halt\n",
+				"(vee-em) the program has stopped with exit status 0\n",
+				"(vee-em) error: the program is not running\n",
+			},
+		},
+		{
+			name:  "step and disasm",
+			input: "step 2\nd\nstack\nreset\nq\nregs\n",
+			expected: []string{
+				"(vee-em) => 0x0015 <f+10> LoadImmediate r1, 3\n",
+				"(vee-em) main:\n   0x0001     CallImmediate 0x000b\n   0x000a     Halt\nf:\n   0x000b     LoadImmediate r0, 7\n=> 0x0015     LoadImmediate r1, 3\n",
+				"(vee-em) the stack is empty\n",
+				"(vee-em) => 0x0001 <main> CallImmediate 0x000b\n(vee-em) ",
+			},
+		},
+		{
+			name:  "reset keeps breakpoints",
+			input: "break f\nc\nreset\nc\n",
+			expected: []string{
+				"(vee-em) breakpoint at 0x000b <f>\n=> 0x000b <f> LoadImmediate r0, 7\n",
+				"(vee-em) => 0x0001 <main> CallImmediate 0x000b\n",
+				"(vee-em) breakpoint at 0x000b <f>\n",
+			},
+		},
+		{
+			name:  "step past the end",
+			input: "step 100\n",
+			expected: []string{
+				"(vee-em) the program has stopped with exit status 0\n",
+			},
+		},
+		{
+			name:  "errors",
+			input: "foo\nbreak g\nbreak 1000\nheap x\nwatch 3 x\n",
+			expected: []string{
+				"error: unknown command \"foo\", see \"help\"\n",
+				"error: unknown symbol: g\n",
+				"error: could not set breakpoint: memory address out of bounds\n",
+				"error: invalid arguments, see \"help\"\n",
+				"error: invalid arguments, see \"help\"\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			d := newDebugger(program, []byte{0x00}, symbols, &out)
+
+			if err := d.repl(strings.NewReader(test.input)); err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			rest := out.String()
+
+			for _, expected := range test.expected {
+				i := strings.Index(rest, expected)
+
+				if i == -1 {
+					t.Fatalf("expected output to contain %q, got %q", expected, out.String())
+				}
+
+				rest = rest[i+len(expected):]
+			}
+
+			if strings.Contains(test.input, "q\n") && rest != "" {
+				t.Fatalf("expected no output after quitting, got %q", rest)
+			}
+		})
+	}
+}
+
+func TestDebuggerInterrupt(t *testing.T) {
+	t.Parallel()
+
+	loop := []byte{0x00, byte(vm.OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0, 1}
+
+	var out bytes.Buffer
+
+	d := newDebugger(loop, []byte{0x00}, nil, &out)
+	d.newContext = func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		return ctx, cancel
+	}
+
+	if err := d.repl(strings.NewReader("c\n")); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), "(vee-em) paused\n=> 0x0001 JmpImmediate 0x0001\n(vee-em) ") {
+		t.Fatalf("expected the program to be paused, got %q", out.String())
+	}
+}
@@ -0,0 +1,72 @@
+// Command vee-em-dbg is an interactive debugger for vee-em bytecode.
+//
+// Usage:
+//
+//	vee-em-dbg [-header hex] [-symbols file] program
+//
+// Type "help" at the prompt for a list of commands.
+// Ctrl-C pauses a program that is running with "continue" or "next".
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+func main() {
+	header := flag.String("header", "", "the magic header of the program, in hexadecimal")
+	symbolsPath := flag.String("symbols", "", "a symbol table file to name addresses with")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: vee-em-dbg [-header hex] [-symbols file] program")
+		os.Exit(2)
+	}
+
+	err := run(flag.Arg(0), *header, *symbolsPath)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(programPath string, header string, symbolsPath string) error {
+	program, err := os.ReadFile(programPath) // #nosec: G304
+
+	if err != nil {
+		return fmt.Errorf("could not read program: %w", err)
+	}
+
+	magicHeader, err := hex.DecodeString(header)
+
+	if err != nil {
+		return fmt.Errorf("invalid magic header: %w", err)
+	}
+
+	var symbols *vm.SymbolTable
+
+	if symbolsPath != "" {
+		f, err := os.Open(symbolsPath) // #nosec: G304
+
+		if err != nil {
+			return fmt.Errorf("could not open symbol table: %w", err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		symbols, err = vm.ReadSymbolTable(f)
+
+		if err != nil {
+			return fmt.Errorf("could not read symbol table: %w", err)
+		}
+	}
+
+	d := newDebugger(program, magicHeader, symbols, os.Stdout)
+
+	return d.repl(os.Stdin)
+}
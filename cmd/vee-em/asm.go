@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/asm"
+)
+
+func asmCommand(args []string, _ io.Writer) error {
+	fs := flag.NewFlagSet("asm", flag.ContinueOnError)
+	output := fs.String("o", "", "the bytecode file to write (default: the source file with a .vem extension)")
+	symbolsPath := fs.String("symbols", "", "a symbol table file to write the labels to")
+	sourceMapPath := fs.String("sourcemap", "", "a source map file to write")
+	isImage := fs.Bool("image", false, "write an image with the data, the symbols and the source map, instead of raw bytecode")
+
+	path, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(path) // #nosec: G304
+
+	if err != nil {
+		return fmt.Errorf("could not read source: %w", err)
+	}
+
+	program, err := asm.AssembleFile(path, string(src))
+
+	if err != nil {
+		return &sourceError{path: path, err: err}
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(path, filepath.Ext(path)) + ".vem"
+	}
+
+	bytecode := program.Bytecode
+
+	if *isImage {
+		bytecode, err = newImage(program)
+
+		if err != nil {
+			return err
+		}
+	} else if len(program.Data) != 0 {
+		return fmt.Errorf("%w: initialized data needs -image", errUsage)
+	}
+
+	err = os.WriteFile(*output, bytecode, 0o600)
+
+	if err != nil {
+		return fmt.Errorf("could not write bytecode: %w", err)
+	}
+
+	if *symbolsPath != "" {
+		err = writeFile(*symbolsPath, func(w io.Writer) error {
+			_, err := program.Symbols.WriteTo(w)
+
+			return err
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if *sourceMapPath != "" {
+		err = writeFile(*sourceMapPath, func(w io.Writer) error {
+			_, err := program.SourceMap.WriteTo(w)
+
+			return err
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newImage encodes an assembled program as an image.
+func newImage(program *asm.Program) ([]byte, error) {
+	if len(program.MagicHeader) != 0 {
+		return nil, fmt.Errorf("%w: an image has no magic header", vm.ErrInvalidMagicHeader)
+	}
+
+	img := vm.NewImage(program.Bytecode)
+	img.Symbols = program.Symbols
+	img.SourceMap = program.SourceMap
+	img.Data = program.Data
+
+	return img.MarshalBinary()
+}
+
+// sourceError prefixes every line of an assembler error with the path,
+// so the errors read as "path:line:column: message".
+type sourceError struct {
+	path string
+	err  error
+}
+
+func (e *sourceError) Error() string {
+	lines := strings.Split(e.err.Error(), "\n")
+
+	for i, line := range lines {
+		lines[i] = e.path + ":" + line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
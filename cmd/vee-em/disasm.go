@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+func disasmCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("disasm", flag.ContinueOnError)
+	header := fs.String("header", "", "the magic header of the program, in hexadecimal")
+	symbolsPath := fs.String("symbols", "", "a symbol table file to name addresses with")
+
+	path, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	program, err := readProgram(path, *header)
+
+	if err != nil {
+		return err
+	}
+
+	symbols, err := program.readSymbols(*symbolsPath)
+
+	if err != nil {
+		return err
+	}
+
+	return disasm.Format(stdout, program.bytecode, len(program.magicHeader), symbols)
+}
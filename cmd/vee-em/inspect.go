@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cmp"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+func inspectCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	header := fs.String("header", "", "the magic header of the program, in hexadecimal")
+
+	path, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	program, err := readProgram(path, *header)
+
+	if err != nil {
+		return err
+	}
+
+	instructions, decodeErr := disasm.Disassemble(program.bytecode, len(program.magicHeader))
+	histogram := map[vm.Opcode]int{}
+
+	for _, instruction := range instructions {
+		histogram[instruction.Opcode]++
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "size          %d bytes\n", program.size)
+
+	if program.image != nil {
+		writeImageInfo(&sb, program.image)
+	} else {
+		fmt.Fprintf(&sb, "header        %d bytes", len(program.magicHeader))
+
+		if len(program.magicHeader) != 0 {
+			fmt.Fprintf(&sb, " (%s)", hex.EncodeToString(program.magicHeader))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "code          %d bytes\n", len(program.bytecode)-len(program.magicHeader))
+	fmt.Fprintf(&sb, "instructions  %d\n", len(instructions))
+
+	if decodeErr != nil {
+		fmt.Fprintf(&sb, "error         %s\n", decodeErr.Error())
+	}
+
+	opcodes := slices.SortedFunc(maps.Keys(histogram), func(a, b vm.Opcode) int {
+		return cmp.Or(cmp.Compare(histogram[b], histogram[a]), cmp.Compare(a, b))
+	})
+
+	if len(opcodes) != 0 {
+		sb.WriteString("\nopcode                        count\n")
+	}
+
+	for _, opcode := range opcodes {
+		fmt.Fprintf(&sb, "%-28s %6d\n", opcode, histogram[opcode])
+	}
+
+	_, err = io.WriteString(stdout, sb.String())
+
+	if err != nil {
+		return fmt.Errorf("could not write output: %w", err)
+	}
+
+	return nil
+}
+
+// writeImageInfo writes the versions and the metadata sections of an image.
+func writeImageInfo(sb *strings.Builder, img *vm.Image) {
+	fmt.Fprintf(sb, "format        image, instruction set %d, features 0x%x\n", img.ISAVersion, uint64(img.Features))
+
+	values := 0
+
+	for _, segment := range img.Data {
+		values += len(segment.Values)
+	}
+
+	fmt.Fprintf(sb, "data          %d segments, %d values\n", len(img.Data), values)
+	fmt.Fprintf(sb, "symbols       %d\n", len(img.Symbols.Symbols()))
+	fmt.Fprintf(sb, "source map    %d mappings\n", len(img.SourceMap.Mappings()))
+
+	if len(img.Imports) != 0 {
+		fmt.Fprintf(sb, "imports       %s\n", strings.Join(img.Imports, ", "))
+	}
+
+	for _, export := range img.Exports.Symbols() {
+		fmt.Fprintf(sb, "export        0x%04x %s\n", export.Address, export.Name)
+	}
+}
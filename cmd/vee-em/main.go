@@ -0,0 +1,133 @@
+// Command vee-em runs, assembles, disassembles and inspects vee-em bytecode.
+//
+// Usage:
+//
+//	vee-em run [-header hex] [-fuel n] [-timeout duration] [-format text|json] program
+//	vee-em asm [-o file] [-symbols file] [-sourcemap file] [-image] source
+//	vee-em disasm [-header hex] [-symbols file] program
+//	vee-em inspect [-header hex] program
+//
+// Programs are either raw bytecode, with an optional magic header,
+// or images, which are detected by their magic number.
+//
+// Run "vee-em <command> -h" for the flags of a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// errUsage is returned when a command is called with the wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+// command defines a subcommand.
+type command struct {
+	// The arguments of the command.
+	usage string
+	// What the command does.
+	description string
+	// Runs the command with the arguments after its name.
+	run func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"run": {
+		usage:       "[-header hex] [-fuel n] [-timeout duration] [-format text|json] program",
+		description: "run a program, and print the final registers",
+		run:         runCommand,
+	},
+	"asm": {
+		usage:       "[-o file] [-symbols file] [-sourcemap file] [-image] source",
+		description: "assemble a source file into bytecode",
+		run:         asmCommand,
+	},
+	"disasm": {
+		usage:       "[-header hex] [-symbols file] program",
+		description: "print a program as assembly source code",
+		run:         disasmCommand,
+	},
+	"inspect": {
+		usage:       "[-header hex] program",
+		description: "print the header, the size and an opcode histogram of a program",
+		run:         inspectCommand,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	cmd, hasCommand := commands[os.Args[1]]
+
+	if !hasCommand {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	err := cmd.run(os.Args[2:], os.Stdout)
+
+	var exitErr *exitStatusError
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+
+	case errors.As(err, &exitErr):
+		os.Exit(exitErr.status)
+
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, err.Error())
+		fmt.Fprintf(os.Stderr, "usage: vee-em %s %s\n", os.Args[1], cmd.usage)
+		os.Exit(2)
+
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	var sb strings.Builder
+
+	sb.WriteString("usage: vee-em <command> [arguments]\n\ncommands:\n")
+
+	names := make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %-8s %s\n", name, commands[name].description)
+	}
+
+	_, _ = io.WriteString(w, sb.String())
+}
+
+// parseFlags parses the flags of a command,
+// and returns the single file argument after them.
+func parseFlags(fs *flag.FlagSet, args []string) (string, error) {
+	fs.SetOutput(os.Stderr)
+
+	err := fs.Parse(args)
+
+	if err != nil {
+		return "", err
+	}
+
+	if fs.NArg() != 1 {
+		return "", errUsage
+	}
+
+	return fs.Arg(0), nil
+}
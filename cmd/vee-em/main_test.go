@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/asm"
+)
+
+const testSource = `.magic "VM"
+main:
+    LoadImmediate r0, 3
+    LoadImmediate r1, 1
+loop:
+    Sub r0, r0, r1
+    JmpImmediateIfNotZero r0, loop
+    LoadImmediate r2, 7
+    HaltRegister r2
+`
+
+// assembleTestProgram assembles testSource into a temporary directory,
+// and returns the directory.
+func assembleTestProgram(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "prog.vasm")
+
+	err := os.WriteFile(src, []byte(testSource), 0o600)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	err = asmCommand(
+		[]string{
+			"-symbols", filepath.Join(dir, "prog.sym"),
+			"-sourcemap", filepath.Join(dir, "prog.map"),
+			src,
+		},
+		&bytes.Buffer{},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	return dir
+}
+
+func TestAsmCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := assembleTestProgram(t)
+	expected, _ := asm.AssembleFile("", testSource)
+
+	for name, content := range map[string]string{
+		"prog.vem": string(expected.Bytecode),
+		"prog.sym": "0x0002 main\n0x0016 loop\n",
+		"prog.map": "0x0002 3 " + filepath.Join(dir, "prog.vasm") + "\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if !strings.HasPrefix(string(b), content) {
+			t.Fatalf("expected %s to start with %q, got %q", name, content, b)
+		}
+	}
+
+	src := filepath.Join(dir, "bad.vasm")
+	_ = os.WriteFile(src, []byte("Nop\nJump 1\nPush r99\n"), 0o600)
+
+	err := asmCommand([]string{"-o", filepath.Join(dir, "bad.vem"), src}, &bytes.Buffer{})
+
+	if !errors.Is(err, asm.ErrUnknownMnemonic) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", asm.ErrUnknownMnemonic, err)
+	}
+
+	expectedMessage := src + ":2:1: unknown mnemonic: Jump\n" +
+		src + ":3:6: invalid operand: expected a register, got r99"
+
+	if err.Error() != expectedMessage {
+		t.Fatalf("expected error message to be %q, got %q", expectedMessage, err.Error())
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := assembleTestProgram(t)
+	prog := filepath.Join(dir, "prog.vem")
+
+	var out bytes.Buffer
+
+	err := runCommand([]string{"-header", "564d", prog}, &out)
+
+	if exitErr := (*exitStatusError)(nil); !errors.As(err, &exitErr) || exitErr.status != 7 {
+		t.Fatalf("expected the exit status 7 to be returned, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"halted with exit status 7 after 10 instructions\n",
+		"r0  0                     r1  1                     r2  7                     r3  0\n",
+		"pc  0x0030  sp 0  zero true  negative false\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected the output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	err = runCommand([]string{"-header", "564d", "-fuel", "4", "-format", "json", prog}, &out)
+
+	if !errors.Is(err, errStopped) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", errStopped, err)
+	}
+
+	var output runOutput
+
+	err = json.Unmarshal(out.Bytes(), &output)
+
+	if err != nil {
+		t.Fatalf("expected valid JSON, got %s", err.Error())
+	}
+
+	if output.Reason != "out of fuel" || output.Instructions != 4 || output.Registers[0] != 2 {
+		t.Fatalf("expected to run out of fuel after 4 instructions, got %+v", output)
+	}
+
+	if !strings.Contains(out.String(), `"error": "out of fuel`) || !strings.Contains(out.String(), `"zero": false`) {
+		t.Fatalf("expected the error in the output, got:\n%s", out.String())
+	}
+
+	err = runCommand([]string{"-header", "0000", prog}, &out)
+
+	if !errors.Is(err, vm.ErrInvalidMagicHeader) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrInvalidMagicHeader, err)
+	}
+
+	err = runCommand([]string{"-format", "xml", prog}, &out)
+
+	if !errors.Is(err, errUsage) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", errUsage, err)
+	}
+
+	err = runCommand([]string{prog, prog}, &out)
+
+	if !errors.Is(err, errUsage) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", errUsage, err)
+	}
+}
+
+func TestDisasmCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := assembleTestProgram(t)
+
+	var out bytes.Buffer
+
+	err := disasmCommand(
+		[]string{"-header", "564d", "-symbols", filepath.Join(dir, "prog.sym"), filepath.Join(dir, "prog.vem")},
+		&out,
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		".magic 0x56, 0x4d\nmain:\n    LoadImmediate r0, 3",
+		"JmpImmediateIfNotZero r0, loop ",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected the output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestInspectCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := assembleTestProgram(t)
+
+	var out bytes.Buffer
+
+	err := inspectCommand([]string{"-header", "564d", filepath.Join(dir, "prog.vem")}, &out)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := "size          48 bytes\n" +
+		"header        2 bytes (564d)\n" +
+		"code          46 bytes\n" +
+		"instructions  6\n" +
+		"\n" +
+		"opcode                        count\n" +
+		"LoadImmediate                     3\n" +
+		"Sub                               1\n" +
+		"JmpImmediateIfNotZero             1\n" +
+		"HaltRegister                      1\n"
+
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestImageProgram(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "prog.vasm")
+	img := filepath.Join(dir, "prog.img")
+
+	_ = os.WriteFile(src, []byte(strings.Replace(testSource, ".magic \"VM\"\n", ".data 3\n.word 4, 5\n", 1)), 0o600)
+
+	err := asmCommand([]string{"-image", "-o", img, src}, &bytes.Buffer{})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	var out bytes.Buffer
+
+	err = runCommand([]string{img}, &out)
+
+	if exitErr := (*exitStatusError)(nil); !errors.As(err, &exitErr) || !strings.HasPrefix(out.String(), "halted with exit status 7") {
+		t.Fatalf("expected the image to run, got %v:\n%s", err, out.String())
+	}
+
+	out.Reset()
+	err = disasmCommand([]string{img}, &out)
+
+	if err != nil || !strings.Contains(out.String(), "JmpImmediateIfNotZero r0, loop ") {
+		t.Fatalf("expected the labels of the image, got %v:\n%s", err, out.String())
+	}
+
+	out.Reset()
+	err = inspectCommand([]string{img}, &out)
+
+	for _, expected := range []string{
+		"format        image, instruction set 1, features 0x0\n",
+		"data          1 segments, 2 values\n",
+		"symbols       2\n",
+		"source map    6 mappings\n",
+		"code          46 bytes\n",
+	} {
+		if err != nil || !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected the output to contain %q, got %v:\n%s", expected, err, out.String())
+		}
+	}
+
+	err = runCommand([]string{"-header", "564d", img}, &out)
+
+	if !errors.Is(err, vm.ErrInvalidMagicHeader) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrInvalidMagicHeader, err)
+	}
+
+	err = asmCommand([]string{"-o", img, src}, &bytes.Buffer{})
+
+	if !errors.Is(err, errUsage) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", errUsage, err)
+	}
+
+	_ = os.WriteFile(src, []byte(testSource), 0o600)
+	err = asmCommand([]string{"-image", "-o", img, src}, &bytes.Buffer{})
+
+	if !errors.Is(err, vm.ErrInvalidMagicHeader) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrInvalidMagicHeader, err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	for status, expected := range map[int64]int{0: 0, 7: 7, 255: 255, 256: 255, -1: 0} {
+		if code := exitCode(status); code != expected {
+			t.Fatalf("expected exit status %d to be exit code %d, got %d", status, expected, code)
+		}
+	}
+}
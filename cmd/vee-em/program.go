@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// loadedProgram defines a program that has been read from a file,
+// which contains either raw bytecode or an image.
+type loadedProgram struct {
+	// The bytecode of the program, including the magic header.
+	bytecode []byte
+	// The magic header of raw bytecode.
+	magicHeader []byte
+	// The image that the program has been loaded from, or nil for raw bytecode.
+	image *vm.Image
+	// The size of the file.
+	size int
+}
+
+// readProgram reads a program file.
+// Images are detected by their magic number. Raw bytecode must start with
+// the hexadecimal magic header, which images cannot have.
+func readProgram(path string, header string) (*loadedProgram, error) {
+	data, err := os.ReadFile(path) // #nosec: G304
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read program: %w", err)
+	}
+
+	magicHeader, err := hex.DecodeString(header)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid magic header: %w", err)
+	}
+
+	if vm.IsImage(data) {
+		if len(magicHeader) != 0 {
+			return nil, fmt.Errorf("%w: an image has no magic header", vm.ErrInvalidMagicHeader)
+		}
+
+		img, err := vm.LoadImage(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &loadedProgram{bytecode: img.Code, magicHeader: nil, image: img, size: len(data)}, nil
+	}
+
+	if !bytes.HasPrefix(data, magicHeader) {
+		return nil, fmt.Errorf("%w: the program does not start with %s", vm.ErrInvalidMagicHeader, header)
+	}
+
+	return &loadedProgram{bytecode: data, magicHeader: magicHeader, image: nil, size: len(data)}, nil
+}
+
+// newVM creates a VM that runs the program.
+func (p *loadedProgram) newVM(options ...vm.Option) *vm.VM {
+	if p.image != nil {
+		return p.image.NewVM(options...)
+	}
+
+	return vm.New(p.bytecode, append([]vm.Option{vm.WithMagicHeader(p.magicHeader)}, options...)...)
+}
+
+// readSymbols reads a symbol table file.
+// When path is empty, the symbols of the image are returned,
+// or nil for raw bytecode, which is an empty symbol table.
+func (p *loadedProgram) readSymbols(path string) (*vm.SymbolTable, error) {
+	if path == "" && p.image != nil {
+		return p.image.Symbols, nil
+	}
+
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path) // #nosec: G304
+
+	if err != nil {
+		return nil, fmt.Errorf("could not open symbol table: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	symbols, err := vm.ReadSymbolTable(f)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read symbol table: %w", err)
+	}
+
+	return symbols, nil
+}
+
+// writeFile creates a file, and writes it with write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path) // #nosec: G304
+
+	if err != nil {
+		return fmt.Errorf("could not create file: %w", err)
+	}
+
+	err = write(f)
+
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+
+	if err != nil {
+		return fmt.Errorf("could not write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// runOutput defines the JSON output of the run command.
+type runOutput struct {
+	Reason       string                 `json:"reason"`
+	ExitStatus   int64                  `json:"exitStatus"`
+	Instructions uint64                 `json:"instructions"`
+	Error        string                 `json:"error,omitempty"`
+	PC           uint64                 `json:"pc"`
+	SP           uint64                 `json:"sp"`
+	Flags        runFlags               `json:"flags"`
+	Registers    [vm.NumRegisters]int64 `json:"registers"`
+}
+
+// runFlags defines the flags in the JSON output of the run command.
+type runFlags struct {
+	Zero     bool `json:"zero"`
+	Negative bool `json:"negative"`
+}
+
+// errStopped is returned when the program stopped with an error,
+// such as a fault or running out of fuel, after the output has been written.
+var errStopped = errors.New("the program stopped with an error")
+
+// exitStatusError is returned when the program halted with a non-zero exit
+// status, after the output has been written.
+// The process exits with the status, clamped to the range 0 to 255.
+type exitStatusError struct {
+	status int
+}
+
+// Error returns the error message, including the exit status.
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("the program exited with status %d", e.status)
+}
+
+func runCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	header := fs.String("header", "", "the magic header of the program, in hexadecimal")
+	fuel := fs.Uint64("fuel", 0, "the maximum number of instructions to execute, or 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "the maximum time to run for, or 0 for no limit")
+	format := fs.String("format", "text", "the output format, text or json")
+
+	path, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	program, err := readProgram(path, *header)
+
+	if err != nil {
+		return err
+	}
+
+	var options []vm.Option
+
+	if *fuel != 0 {
+		options = append(options, vm.WithFuel(*fuel, nil))
+	}
+
+	ctx := context.Background()
+
+	if *timeout != 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	v := program.newVM(options...)
+	result, runErr := v.RunContext(ctx)
+
+	output := runOutput{
+		Reason:       result.Reason.String(),
+		ExitStatus:   result.ExitStatus,
+		Instructions: result.Instructions,
+		Error:        "",
+		PC:           v.PC(),
+		SP:           v.SP(),
+		Flags: runFlags{
+			Zero:     v.Flags().IsZero,
+			Negative: v.Flags().IsNegative,
+		},
+		Registers: v.Registers(),
+	}
+
+	if runErr != nil {
+		output.Error = runErr.Error()
+	}
+
+	if *format == "json" {
+		err = writeJSON(stdout, output)
+	} else {
+		err = writeText(stdout, output)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write output: %w", err)
+	}
+
+	if output.Error != "" {
+		return errStopped
+	}
+
+	if output.ExitStatus != 0 {
+		return &exitStatusError{status: exitCode(output.ExitStatus)}
+	}
+
+	return nil
+}
+
+// exitCode clamps an exit status to the range of process exit codes.
+func exitCode(status int64) int {
+	return int(min(max(status, 0), 255))
+}
+
+func writeJSON(w io.Writer, output runOutput) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(output)
+}
+
+func writeText(w io.Writer, output runOutput) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(
+		&sb,
+		"%s with exit status %d after %d instructions\n",
+		output.Reason,
+		output.ExitStatus,
+		output.Instructions,
+	)
+
+	if output.Error != "" {
+		fmt.Fprintf(&sb, "error: %s\n", output.Error)
+	}
+
+	for i, value := range output.Registers {
+		if i%4 == 3 {
+			fmt.Fprintf(&sb, "r%-2d %d\n", i, value)
+		} else {
+			fmt.Fprintf(&sb, "r%-2d %-20d  ", i, value)
+		}
+	}
+
+	fmt.Fprintf(
+		&sb,
+		"pc  0x%04x  sp %d  zero %t  negative %t\n",
+		output.PC,
+		output.SP,
+		output.Flags.Zero,
+		output.Flags.Negative,
+	)
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
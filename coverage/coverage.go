@@ -0,0 +1,123 @@
+// Package coverage provides bytecode coverage reporting for vee-em programs.
+package coverage
+
+import vm "github.com/Dobefu/vee-em"
+
+// Branch defines how often a conditional jump has been taken or not taken.
+type Branch struct {
+	// The number of times that the jump has been taken.
+	Taken uint64
+	// The number of times that the jump has not been taken.
+	NotTaken uint64
+}
+
+// Coverage records the executed instructions and the taken and not-taken
+// edges of the conditional jumps in a program.
+type Coverage struct {
+	// The number of executions per instruction start address.
+	instructions map[uint64]uint64
+	// The edges of the conditional jumps, per instruction start address.
+	branches map[uint64]Branch
+}
+
+// New creates a new, empty coverage.
+func New() *Coverage {
+	return &Coverage{
+		instructions: map[uint64]uint64{},
+		branches:     map[uint64]Branch{},
+	}
+}
+
+// TraceHook returns the trace hook that records coverage.
+// Pass it to vm.WithTraceHook to record the coverage of a VM.
+func (c *Coverage) TraceHook() vm.TraceHook {
+	return vm.TraceHook{
+		Before: nil,
+		After:  c.after,
+	}
+}
+
+func (c *Coverage) after(event *vm.TraceEvent) error {
+	c.instructions[event.PC]++
+
+	if event.Err != nil || !IsConditionalJump(event.Opcode) {
+		return nil
+	}
+
+	branch := c.branches[event.PC]
+
+	// A jump to the next instruction cannot be told apart from a jump
+	// that is not taken, so it is counted as not taken.
+	if event.NextPC != event.PC+vm.GetInstructionLen(event.Opcode) {
+		branch.Taken++
+	} else {
+		branch.NotTaken++
+	}
+
+	c.branches[event.PC] = branch
+
+	return nil
+}
+
+// IsConditionalJump returns whether the opcode is a conditional jump,
+// from OpcodeJmpImmediateIfZero up to and including
+// OpcodeJmpRegisterIfLessOrEqual.
+func IsConditionalJump(opcode vm.Opcode) bool {
+	if opcode == vm.OpcodeJmpRegister {
+		return false
+	}
+
+	return opcode >= vm.OpcodeJmpImmediateIfZero &&
+		opcode <= vm.OpcodeJmpRegisterIfLessOrEqual
+}
+
+// Count returns how often the instruction at addr has been executed.
+func (c *Coverage) Count(addr uint64) uint64 {
+	return c.instructions[addr]
+}
+
+// Branch returns the edges of the conditional jump at addr.
+// The boolean is false when the jump has never been executed.
+func (c *Coverage) Branch(addr uint64) (Branch, bool) {
+	branch, hasBranch := c.branches[addr]
+
+	return branch, hasBranch
+}
+
+// Instructions returns the number of executions per instruction start address.
+func (c *Coverage) Instructions() map[uint64]uint64 {
+	instructions := make(map[uint64]uint64, len(c.instructions))
+
+	for addr, count := range c.instructions {
+		instructions[addr] = count
+	}
+
+	return instructions
+}
+
+// Branches returns the edges of the executed conditional jumps,
+// per instruction start address.
+func (c *Coverage) Branches() map[uint64]Branch {
+	branches := make(map[uint64]Branch, len(c.branches))
+
+	for addr, branch := range c.branches {
+		branches[addr] = branch
+	}
+
+	return branches
+}
+
+// Merge adds the coverage of other to c,
+// which is used to combine the coverage of multiple runs.
+func (c *Coverage) Merge(other *Coverage) {
+	for addr, count := range other.instructions {
+		c.instructions[addr] += count
+	}
+
+	for addr, branch := range other.branches {
+		merged := c.branches[addr]
+		merged.Taken += branch.Taken
+		merged.NotTaken += branch.NotTaken
+		c.branches[addr] = merged
+	}
+}
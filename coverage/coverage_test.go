@@ -0,0 +1,182 @@
+package coverage
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+var program = []byte{
+	byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 2,
+	byte(vm.OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 1,
+	byte(vm.OpcodeSub), 0, 0, 1,
+	byte(vm.OpcodeJmpImmediateIfNotZero), 0, 0, 0, 0, 0, 0, 0, 0, 20,
+	byte(vm.OpcodeJmpImmediateIfZero), 0, 0, 0, 0, 0, 0, 0, 0, 45,
+	byte(vm.OpcodeHalt),
+	byte(vm.OpcodeNop),
+}
+
+func runCoverage(t *testing.T) *Coverage {
+	t.Helper()
+
+	c := New()
+
+	if _, err := vm.New(program, vm.WithTraceHook(c.TraceHook())).Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	return c
+}
+
+func TestCoverage(t *testing.T) {
+	t.Parallel()
+
+	c := runCoverage(t)
+
+	expected := map[uint64]uint64{0: 1, 10: 1, 20: 2, 24: 2, 34: 1, 45: 1}
+
+	if !reflect.DeepEqual(c.Instructions(), expected) {
+		t.Fatalf("expected instructions to be %v, got %v", expected, c.Instructions())
+	}
+
+	expectedBranches := map[uint64]Branch{
+		24: {Taken: 1, NotTaken: 1},
+		34: {Taken: 1, NotTaken: 0},
+	}
+
+	if !reflect.DeepEqual(c.Branches(), expectedBranches) {
+		t.Fatalf("expected branches to be %v, got %v", expectedBranches, c.Branches())
+	}
+
+	c.Merge(runCoverage(t))
+
+	if branch, _ := c.Branch(24); c.Count(20) != 4 || branch.Taken != 2 {
+		t.Fatalf("expected merged coverage to be doubled, got %v and %v", c.Count(20), branch)
+	}
+}
+
+func TestIsConditionalJump(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opcode   vm.Opcode
+		expected bool
+	}{
+		{opcode: vm.OpcodeJmpImmediate, expected: false},
+		{opcode: vm.OpcodeJmpImmediateIfZero, expected: true},
+		{opcode: vm.OpcodeJmpRegister, expected: false},
+		{opcode: vm.OpcodeJmpRegisterIfEqual, expected: true},
+		{opcode: vm.OpcodeJmpRegisterIfLessOrEqual, expected: true},
+		{opcode: vm.OpcodeCallImmediate, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.opcode.String(), func(t *testing.T) {
+			t.Parallel()
+
+			if IsConditionalJump(test.opcode) != test.expected {
+				t.Fatalf("expected %t, got %t", test.expected, !test.expected)
+			}
+		})
+	}
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	if _, err := runCoverage(t).WriteTo(&buf); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := "mode: vee-em\n" +
+		"instruction 0x0000 1\n" +
+		"instruction 0x000a 1\n" +
+		"instruction 0x0014 2\n" +
+		"instruction 0x0018 2\n" +
+		"instruction 0x0022 1\n" +
+		"instruction 0x002d 1\n" +
+		"branch 0x0018 1 1\n" +
+		"branch 0x0022 1 0\n"
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	c, err := ReadProfile(strings.NewReader(expected + expected[len("mode: vee-em\n"):]))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if branch, _ := c.Branch(0x22); c.Count(0x14) != 4 || branch.Taken != 2 {
+		t.Fatalf("expected repeated records to be merged, got %v", c.Instructions())
+	}
+}
+
+func TestReadProfileErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "missing header", input: "instruction 0 1\n"},
+		{name: "unknown record", input: "mode: vee-em\nline 0 1\n"},
+		{name: "missing count", input: "mode: vee-em\ninstruction 0\n"},
+		{name: "invalid number", input: "mode: vee-em\nbranch 0 x 1\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ReadProfile(strings.NewReader(test.input)); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	if err := runCoverage(t).WriteHTML(&buf, disasm.Listing(program, 0, nil)); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	for _, s := range []string{
+		"6 of 7 instructions covered, 3 of 4 branch edges covered",
+		`<tr class="partial"><td>0x0022</td><td>1</td><td>    JmpImmediateIfZero r0, 0x002d</td><td>taken 1, not taken 0</td></tr>`,
+		`<tr class="uncovered"><td>0x002c</td><td>0</td><td>    Halt</td><td></td></tr>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("expected the report to contain %q, got %s", s, buf.String())
+		}
+	}
+}
+
+func TestWriteHTMLNotRun(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	if err := New().WriteHTML(&buf, disasm.Listing(program, 0, nil)); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := `<tr class="uncovered"><td>0x0018</td><td>0</td><td>    JmpImmediateIfNotZero r0, 0x0014</td>` +
+		`<td>taken 0, not taken 0</td></tr>`
+
+	if !strings.Contains(buf.String(), expected) || strings.Contains(buf.String(), `class="partial"`) {
+		t.Fatalf("expected conditional jumps that never ran to be uncovered, got %s", buf.String())
+	}
+}
@@ -0,0 +1,114 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// profileHeader is the first line of a coverage profile.
+const profileHeader = "mode: vee-em"
+
+// WriteTo writes the coverage in a text format, which can be read back with
+// ReadProfile. After a header line, every line is either
+// "instruction <address> <count>" or
+// "branch <address> <taken> <not taken>", sorted by address.
+func (c *Coverage) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	sb.WriteString(profileHeader + "\n")
+
+	for _, addr := range slices.Sorted(maps.Keys(c.instructions)) {
+		fmt.Fprintf(&sb, "instruction 0x%04x %d\n", addr, c.instructions[addr])
+	}
+
+	for _, addr := range slices.Sorted(maps.Keys(c.branches)) {
+		branch := c.branches[addr]
+		fmt.Fprintf(&sb, "branch 0x%04x %d %d\n", addr, branch.Taken, branch.NotTaken)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	if err != nil {
+		return int64(n), fmt.Errorf("could not write coverage: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// ReadProfile reads a coverage profile in the text format that is written by
+// WriteTo. Profiles of multiple runs can be combined with Merge.
+func ReadProfile(r io.Reader) (*Coverage, error) {
+	c := New()
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if lineNum == 1 {
+			if line != profileHeader {
+				return nil, fmt.Errorf("line %d: expected %q", lineNum, profileHeader)
+			}
+
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+
+		err := c.parseLine(strings.Fields(line))
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+
+	err := scanner.Err()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read coverage: %w", err)
+	}
+
+	if lineNum == 0 {
+		return nil, fmt.Errorf("line 1: expected %q", profileHeader)
+	}
+
+	return c, nil
+}
+
+func (c *Coverage) parseLine(fields []string) error {
+	values := make([]uint64, len(fields)-1)
+
+	for i, field := range fields[1:] {
+		value, err := strconv.ParseUint(field, 0, 64)
+
+		if err != nil {
+			return fmt.Errorf("invalid number: %w", err)
+		}
+
+		values[i] = value
+	}
+
+	switch {
+	case fields[0] == "instruction" && len(values) == 2:
+		c.instructions[values[0]] += values[1]
+
+	case fields[0] == "branch" && len(values) == 3:
+		branch := c.branches[values[0]]
+		branch.Taken += values[1]
+		branch.NotTaken += values[2]
+		c.branches[values[0]] = branch
+
+	default:
+		return fmt.Errorf("unexpected record %q", strings.Join(fields, " "))
+	}
+
+	return nil
+}
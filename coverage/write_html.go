@@ -0,0 +1,122 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/Dobefu/vee-em/disasm"
+)
+
+// htmlLine is a line of the HTML report.
+type htmlLine struct {
+	Class   string
+	Address string
+	Count   string
+	Text    string
+	Branch  string
+}
+
+// htmlReport is the data that is passed to htmlTemplate.
+type htmlReport struct {
+	Lines           []htmlLine
+	Instructions    int
+	Covered         int
+	BranchEdges     int
+	CoveredBranches int
+}
+
+var htmlTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>vee-em coverage</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+td { padding: 0 1em; white-space: pre; }
+.covered { background: #c8f0c8; }
+.partial { background: #f0e8a0; }
+.uncovered { background: #f0c0c0; }
+</style>
+</head>
+<body>
+<p>{{.Covered}} of {{.Instructions}} instructions covered, {{.CoveredBranches}} of {{.BranchEdges}} branch edges covered</p>
+<table>
+{{- range .Lines}}
+<tr class="{{.Class}}"><td>{{.Address}}</td><td>{{.Count}}</td><td>{{.Text}}</td><td>{{.Branch}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes an HTML report, which shows the coverage of every line in
+// the listing. Lines are marked as covered, uncovered, or partially covered
+// when only one edge of a conditional jump has been taken.
+func (c *Coverage) WriteHTML(w io.Writer, lines []disasm.Line) error {
+	report := htmlReport{
+		Lines:           make([]htmlLine, len(lines)),
+		Instructions:    0,
+		Covered:         0,
+		BranchEdges:     0,
+		CoveredBranches: 0,
+	}
+
+	for i, line := range lines {
+		report.Lines[i] = c.htmlLine(line, &report)
+	}
+
+	err := htmlTemplate.Execute(w, report)
+
+	if err != nil {
+		return fmt.Errorf("could not write coverage report: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Coverage) htmlLine(line disasm.Line, report *htmlReport) htmlLine {
+	if !line.IsInstruction {
+		return htmlLine{Class: "", Address: "", Count: "", Text: line.Text, Branch: ""}
+	}
+
+	count := c.instructions[line.Address]
+	result := htmlLine{
+		Class:   "uncovered",
+		Address: fmt.Sprintf("0x%04x", line.Address),
+		Count:   fmt.Sprintf("%d", count),
+		Text:    line.Text,
+		Branch:  "",
+	}
+
+	report.Instructions++
+
+	if count > 0 {
+		report.Covered++
+		result.Class = "covered"
+	}
+
+	if !IsConditionalJump(line.Opcode) {
+		return result
+	}
+
+	branch := c.branches[line.Address]
+
+	report.BranchEdges += 2
+	result.Branch = fmt.Sprintf("taken %d, not taken %d", branch.Taken, branch.NotTaken)
+
+	if branch.Taken > 0 {
+		report.CoveredBranches++
+	}
+
+	if branch.NotTaken > 0 {
+		report.CoveredBranches++
+	}
+
+	if count > 0 && (branch.Taken == 0 || branch.NotTaken == 0) {
+		result.Class = "partial"
+	}
+
+	return result
+}
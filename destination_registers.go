@@ -0,0 +1,41 @@
+package vm
+
+// Note: The offset is relative to the start of the instruction.
+var destinationRegisterOffsets = map[Opcode]uint64{
+	OpcodePop:                  1,
+	OpcodeLoadImmediate:        1,
+	OpcodeLoadRegister:         1,
+	OpcodeLoadMemory:           1,
+	OpcodeAdd:                  1,
+	OpcodeSub:                  1,
+	OpcodeMul:                  1,
+	OpcodeDiv:                  1,
+	OpcodeMod:                  1,
+	OpcodeAND:                  1,
+	OpcodeOR:                   1,
+	OpcodeXOR:                  1,
+	OpcodeNOT:                  1,
+	OpcodeShiftLeft:            1,
+	OpcodeShiftRight:           1,
+	OpcodeShiftRightArithmetic: 1,
+	OpcodeHostCall:             9,
+}
+
+// writtenRegisters returns the registers that an instruction writes to.
+// The returned slice is backed by the VM, and only valid until the next step.
+func (v *VM) writtenRegisters(
+	opcode Opcode,
+	instructionStart register,
+) []register {
+	offset, hasDestination := destinationRegisterOffsets[opcode]
+
+	if !hasDestination {
+		return nil
+	}
+
+	v.writtenRegistersBuf[0] = register(
+		v.program[instructionStart+offset],
+	) & NumRegistersMask
+
+	return v.writtenRegistersBuf[:1]
+}
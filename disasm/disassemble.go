@@ -0,0 +1,137 @@
+// Package disasm decodes vee-em bytecode into instructions,
+// and formats them as source code that the asm package assembles
+// into the same bytecode.
+package disasm
+
+import (
+	"fmt"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// Instruction defines a single decoded instruction.
+type Instruction struct {
+	// The address of the instruction, including the magic header.
+	Address uint64
+	// The opcode of the instruction.
+	Opcode vm.Opcode
+	// The decoded operands, in the order in which they are encoded.
+	Operands []vm.Operand
+	// The raw bytes of the instruction.
+	Bytes []byte
+}
+
+// Error describes bytes that could not be decoded as an instruction.
+type Error struct {
+	// The kind of error, which is vm.ErrUnknownOpcode,
+	// vm.ErrTruncatedInstruction, vm.ErrInvalidRegister or vm.ErrOutOfBounds.
+	Err error
+	// The address of the bytes that could not be decoded.
+	Address uint64
+}
+
+// Error returns the error message, including the address.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at address 0x%04x", e.Err.Error(), e.Address)
+}
+
+// Unwrap returns the kind of error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decode decodes the instruction that starts at addr in the program.
+// Register and count operands of 32 or more are not valid,
+// even though the VM masks them when executing the instruction,
+// since they cannot be written in assembly.
+func Decode(program []byte, addr uint64) (Instruction, error) {
+	operands, err := vm.DecodeOperands(program, addr)
+
+	if err != nil {
+		return Instruction{}, &Error{Err: err, Address: addr}
+	}
+
+	opcode := vm.Opcode(program[addr])
+	offset := addr + 1
+
+	for _, operand := range operands {
+		isByte := operand.Kind == vm.OperandRegister || operand.Kind == vm.OperandCount
+
+		if isByte && program[offset] >= vm.NumRegisters {
+			return Instruction{}, &Error{Err: vm.ErrInvalidRegister, Address: addr}
+		}
+
+		offset += operand.Kind.Size()
+	}
+
+	return Instruction{
+		Address:  addr,
+		Opcode:   opcode,
+		Operands: operands,
+		Bytes:    program[addr : addr+vm.GetInstructionLen(opcode)],
+	}, nil
+}
+
+// Disassemble decodes all instructions in the program,
+// after a magic header of headerLen bytes.
+// When the program contains bytes that cannot be decoded,
+// the instructions before them are returned along with an *Error.
+func Disassemble(program []byte, headerLen int) ([]Instruction, error) {
+	if headerLen < 0 || headerLen > len(program) {
+		return nil, &Error{Err: vm.ErrOutOfBounds, Address: 0}
+	}
+
+	var instructions []Instruction
+
+	for addr := uint64(headerLen); addr < uint64(len(program)); {
+		instruction, err := Decode(program, addr)
+
+		if err != nil {
+			return instructions, err
+		}
+
+		instructions = append(instructions, instruction)
+		addr += uint64(len(instruction.Bytes))
+	}
+
+	return instructions, nil
+}
+
+// String returns the instruction in assembly syntax,
+// such as "LoadImmediate r0, 42" or "JmpImmediate 0x0010".
+func (i Instruction) String() string {
+	return i.format(nil)
+}
+
+// format returns the instruction in assembly syntax,
+// with the address operands that have a label replaced by it.
+func (i Instruction) format(labels map[uint64]string) string {
+	formatted := make([]string, len(i.Operands))
+
+	for j, operand := range i.Operands {
+		switch operand.Kind {
+		case vm.OperandRegister:
+			formatted[j] = fmt.Sprintf("r%d", operand.Value)
+
+		case vm.OperandAddress:
+			// #nosec: G115
+			label, hasLabel := labels[uint64(operand.Value)]
+
+			if hasLabel {
+				formatted[j] = label
+			} else {
+				formatted[j] = fmt.Sprintf("0x%04x", uint64(operand.Value)) // #nosec: G115
+			}
+
+		case vm.OperandImmediate, vm.OperandCount:
+			formatted[j] = fmt.Sprintf("%d", operand.Value)
+		}
+	}
+
+	if len(formatted) == 0 {
+		return i.Opcode.String()
+	}
+
+	return i.Opcode.String() + " " + strings.Join(formatted, ", ")
+}
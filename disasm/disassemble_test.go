@@ -0,0 +1,208 @@
+package disasm
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/asm"
+)
+
+var testProgram = []byte{
+	'V', 'M',
+	byte(vm.OpcodeLoadImmediate), 0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+	byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 23,
+	byte(vm.OpcodeHaltRegister), 0,
+	byte(vm.OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 3, 1, 2,
+	byte(vm.OpcodeJmpImmediateIfNotZero), 1, 0, 0, 0, 0, 0, 0, 0, 44,
+	byte(vm.OpcodeReturn),
+	byte(vm.OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0x10, 0x00,
+}
+
+func TestDisassemble(t *testing.T) {
+	t.Parallel()
+
+	instructions, err := Disassemble(testProgram, 2)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := []string{
+		"LoadImmediate r0, -1",
+		"CallImmediate 0x0017",
+		"HaltRegister r0",
+		"HostCall 3, r1, 2",
+		"JmpImmediateIfNotZero r1, 0x002c",
+		"Return",
+		"JmpImmediate 0x1000",
+	}
+
+	if len(instructions) != len(expected) {
+		t.Fatalf("expected %d instructions, got %d", len(expected), len(instructions))
+	}
+
+	for i, instruction := range instructions {
+		if instruction.String() != expected[i] {
+			t.Fatalf("expected \"%s\", got \"%s\"", expected[i], instruction.String())
+		}
+	}
+
+	expectedInstruction := Instruction{
+		Address:  23,
+		Opcode:   vm.OpcodeHostCall,
+		Operands: []vm.Operand{{Kind: vm.OperandImmediate, Value: 3}, {Kind: vm.OperandRegister, Value: 1}, {Kind: vm.OperandCount, Value: 2}},
+		Bytes:    testProgram[23:34],
+	}
+
+	if !reflect.DeepEqual(instructions[3], expectedInstruction) {
+		t.Fatalf("expected %+v, got %+v", expectedInstruction, instructions[3])
+	}
+}
+
+func TestDisassembleErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		program   []byte
+		headerLen int
+		expected  error
+		address   uint64
+		count     int
+	}{
+		{
+			name:      "unknown opcode",
+			program:   []byte{byte(vm.OpcodeNop), 0xFF},
+			headerLen: 0,
+			expected:  vm.ErrUnknownOpcode,
+			address:   1,
+			count:     1,
+		},
+		{
+			name:      "truncated instruction",
+			program:   []byte{0, byte(vm.OpcodeLoadImmediate), 0, 0},
+			headerLen: 1,
+			expected:  vm.ErrTruncatedInstruction,
+			address:   1,
+			count:     0,
+		},
+		{
+			name:      "register out of range",
+			program:   []byte{byte(vm.OpcodeNop), byte(vm.OpcodeAdd), 0, 1, 32},
+			headerLen: 0,
+			expected:  vm.ErrInvalidRegister,
+			address:   1,
+			count:     1,
+		},
+		{
+			name:      "count out of range",
+			program:   []byte{byte(vm.OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 1, 0, 255},
+			headerLen: 0,
+			expected:  vm.ErrInvalidRegister,
+			address:   0,
+			count:     0,
+		},
+		{
+			name:      "header too long",
+			program:   []byte{byte(vm.OpcodeNop)},
+			headerLen: 2,
+			expected:  vm.ErrOutOfBounds,
+			address:   0,
+			count:     0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			instructions, err := Disassemble(test.program, test.headerLen)
+
+			var disasmErr *Error
+
+			if !errors.As(err, &disasmErr) || !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+
+			if disasmErr.Address != test.address || len(instructions) != test.count {
+				t.Fatalf(
+					"expected %d instructions and an error at %d, got %d and %d",
+					test.count,
+					test.address,
+					len(instructions),
+					disasmErr.Address,
+				)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	symbols := vm.NewSymbolTable([]vm.Symbol{
+		{Name: "main", Address: 2},
+		{Name: "f", Address: 23},
+		{Name: "r1", Address: 34},
+		{Name: "inside", Address: 3},
+		{Name: "end", Address: 54},
+	})
+
+	var buf bytes.Buffer
+
+	err := Format(&buf, testProgram, 2, symbols)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	src := buf.String()
+
+	for _, expected := range []string{
+		".magic 0x56, 0x4d\n",
+		"main:\n    LoadImmediate r0, -1",
+		"CallImmediate f ",
+		"L002c:\n    Return",
+		"JmpImmediate 0x1000 ",
+		"; 0x002c\n",
+		"end:\n",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Fatalf("expected the disassembly to contain %q, got:\n%s", expected, src)
+		}
+	}
+
+	if strings.Contains(src, "r1:") || strings.Contains(src, "inside:") {
+		t.Fatalf("expected invalid labels to be left out, got:\n%s", src)
+	}
+
+	program, err := asm.AssembleFile("", src)
+
+	if err != nil {
+		t.Fatalf("expected the disassembly to assemble, got %s", err.Error())
+	}
+
+	if !bytes.Equal(program.Bytecode, testProgram) || !bytes.Equal(program.MagicHeader, []byte("VM")) {
+		t.Fatalf("expected %v, got %v", testProgram, program.Bytecode)
+	}
+}
+
+func TestFormatErr(t *testing.T) {
+	t.Parallel()
+
+	err := Format(&bytes.Buffer{}, []byte{0xFF}, 0, nil)
+
+	if !errors.Is(err, vm.ErrUnknownOpcode) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrUnknownOpcode, err)
+	}
+
+	err = Format(&bytes.Buffer{}, []byte{byte(vm.OpcodePush), 33}, 0, nil)
+
+	if !errors.Is(err, vm.ErrInvalidRegister) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrInvalidRegister, err)
+	}
+}
@@ -0,0 +1,107 @@
+package disasm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	vm "github.com/Dobefu/vee-em"
+	"github.com/Dobefu/vee-em/asm"
+)
+
+// Format writes the program as assembly source code,
+// which the asm package assembles into the same bytecode.
+// The magic header of headerLen bytes is written as a .magic directive.
+// The symbols are written as labels, and the targets of jumps and calls
+// without a symbol get a label such as L0010. symbols may be nil.
+// When the program contains bytes that cannot be decoded,
+// such as a register operand of 32 or more, an *Error is returned.
+func Format(w io.Writer, program []byte, headerLen int, symbols *vm.SymbolTable) error {
+	instructions, err := Disassemble(program, headerLen)
+
+	if err != nil {
+		return err
+	}
+
+	labels, labelLines := makeLabels(program, instructions, symbols)
+	var sb strings.Builder
+
+	if headerLen > 0 {
+		header := make([]string, headerLen)
+
+		for i, b := range program[:headerLen] {
+			header[i] = fmt.Sprintf("0x%02x", b)
+		}
+
+		fmt.Fprintf(&sb, ".magic %s\n", strings.Join(header, ", "))
+	}
+
+	for _, instruction := range instructions {
+		for _, label := range labelLines[instruction.Address] {
+			fmt.Fprintf(&sb, "%s:\n", label)
+		}
+
+		fmt.Fprintf(&sb, "    %-40s ; 0x%04x\n", instruction.format(labels), instruction.Address)
+	}
+
+	for _, label := range labelLines[uint64(len(program))] {
+		fmt.Fprintf(&sb, "%s:\n", label)
+	}
+
+	_, err = io.WriteString(w, sb.String())
+
+	if err != nil {
+		return fmt.Errorf("could not write disassembly: %w", err)
+	}
+
+	return nil
+}
+
+// makeLabels returns the label that replaces an address operand,
+// and the labels to write before the instruction, per address.
+// Labels can only be written at the start of an instruction,
+// or at the end of the program.
+func makeLabels(
+	program []byte,
+	instructions []Instruction,
+	symbols *vm.SymbolTable,
+) (map[uint64]string, map[uint64][]string) {
+	labels := map[uint64]string{}
+	labelLines := map[uint64][]string{}
+	names := map[string]bool{}
+
+	isLabelAddress := map[uint64]bool{uint64(len(program)): true}
+
+	for _, instruction := range instructions {
+		isLabelAddress[instruction.Address] = true
+	}
+
+	addLabel := func(addr uint64, name string) {
+		if !isLabelAddress[addr] || !asm.IsLabelName(name) || names[name] {
+			return
+		}
+
+		if _, hasLabel := labels[addr]; !hasLabel {
+			labels[addr] = name
+		}
+
+		labelLines[addr] = append(labelLines[addr], name)
+		names[name] = true
+	}
+
+	for _, symbol := range symbols.Symbols() {
+		addLabel(symbol.Address, symbol.Name)
+	}
+
+	for _, instruction := range instructions {
+		for _, operand := range instruction.Operands {
+			addr := uint64(operand.Value) // #nosec: G115
+
+			if _, hasLabel := labels[addr]; operand.Kind == vm.OperandAddress && !hasLabel {
+				addLabel(addr, fmt.Sprintf("L%04x", addr))
+			}
+		}
+	}
+
+	return labels, labelLines
+}
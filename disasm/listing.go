@@ -0,0 +1,73 @@
+package disasm
+
+import (
+	"fmt"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+// Line defines a single line of a listing, such as a line of a disassembly
+// or of assembly source code.
+type Line struct {
+	// The text of the line.
+	Text string
+	// The address of the instruction that the line contains.
+	Address uint64
+	// The opcode of the instruction that the line contains.
+	Opcode vm.Opcode
+	// Whether the line contains an instruction.
+	// Labels, comments and bytes that cannot be decoded
+	// do not contain an instruction.
+	IsInstruction bool
+}
+
+// Listing creates a listing of the instructions in the program,
+// starting at start, which is usually the length of the magic header.
+// When symbols is not nil, a label line is added for every symbol.
+// Bytes that cannot be decoded are listed as .byte directives.
+func Listing(program []byte, start uint64, symbols *vm.SymbolTable) []Line {
+	var lines []Line
+
+	labels := map[uint64][]string{}
+
+	for _, symbol := range symbols.Symbols() {
+		labels[symbol.Address] = append(labels[symbol.Address], symbol.Name)
+	}
+
+	for addr := start; addr < uint64(len(program)); {
+		for _, label := range labels[addr] {
+			lines = append(lines, Line{
+				Text:          label + ":",
+				Address:       addr,
+				Opcode:        vm.OpcodeNop,
+				IsInstruction: false,
+			})
+		}
+
+		instruction, err := Decode(program, addr)
+
+		if err != nil {
+			lines = append(lines, Line{
+				Text:          fmt.Sprintf("    .byte 0x%02x", program[addr]),
+				Address:       addr,
+				Opcode:        vm.OpcodeNop,
+				IsInstruction: false,
+			})
+
+			addr++
+
+			continue
+		}
+
+		lines = append(lines, Line{
+			Text:          "    " + instruction.String(),
+			Address:       addr,
+			Opcode:        instruction.Opcode,
+			IsInstruction: true,
+		})
+
+		addr += uint64(len(instruction.Bytes))
+	}
+
+	return lines
+}
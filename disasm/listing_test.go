@@ -0,0 +1,36 @@
+package disasm
+
+import (
+	"reflect"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+func TestListing(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		'V', 'M',
+		byte(vm.OpcodeNop),
+		byte(vm.OpcodePush), 1,
+		0xff,
+		byte(vm.OpcodeHalt),
+	}
+
+	symbols := vm.NewSymbolTable([]vm.Symbol{{Name: "main", Address: 2}, {Name: "end", Address: 6}})
+	lines := Listing(program, 2, symbols)
+
+	expected := []Line{
+		{Text: "main:", Address: 2, Opcode: vm.OpcodeNop, IsInstruction: false},
+		{Text: "    Nop", Address: 2, Opcode: vm.OpcodeNop, IsInstruction: true},
+		{Text: "    Push r1", Address: 3, Opcode: vm.OpcodePush, IsInstruction: true},
+		{Text: "    .byte 0xff", Address: 5, Opcode: vm.OpcodeNop, IsInstruction: false},
+		{Text: "end:", Address: 6, Opcode: vm.OpcodeNop, IsInstruction: false},
+		{Text: "    Halt", Address: 6, Opcode: vm.OpcodeHalt, IsInstruction: true},
+	}
+
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected %v, got %v", expected, lines)
+	}
+}
@@ -0,0 +1,59 @@
+package vm
+
+import (
+	"errors"
+)
+
+var (
+	// ErrCanceled is returned when execution stops because the context
+	// has been canceled.
+	ErrCanceled = errors.New("execution canceled")
+	// ErrDeadlineExceeded is returned when execution stops because the
+	// deadline of the context has passed.
+	ErrDeadlineExceeded = errors.New("execution deadline exceeded")
+	// ErrOutOfFuel is returned when there is not enough fuel left to execute
+	// the next instruction.
+	ErrOutOfFuel = errors.New("out of fuel")
+	// ErrInvalidSnapshot is returned when a snapshot cannot be restored,
+	// because it is malformed or does not fit the VM.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+	// ErrProgramMismatch is returned when a snapshot is restored into a VM
+	// that runs a different program than the one the snapshot was taken of.
+	ErrProgramMismatch = errors.New("snapshot program mismatch")
+	// ErrNoHistory is returned when there are no recorded instructions left
+	// to undo.
+	ErrNoHistory = errors.New("no recorded history")
+	// ErrInvalidImage is returned when an image cannot be loaded,
+	// because it is malformed or needs a newer version of the VM.
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrInvalidMagicHeader is returned when the program does not start with
+	// the configured magic header.
+	ErrInvalidMagicHeader = errors.New("invalid magic header")
+	// ErrInvalidRegister is returned when a register index is not below
+	// NumRegisters.
+	ErrInvalidRegister = errors.New("invalid register")
+
+	// ErrStackOverflow is the fault kind for pushing onto a full stack.
+	ErrStackOverflow = errors.New("stack overflow")
+	// ErrStackUnderflow is the fault kind for popping off an empty stack.
+	ErrStackUnderflow = errors.New("stack underflow")
+	// ErrCallStackOverflow is the fault kind for calling a function when the
+	// maximum call depth has been reached.
+	ErrCallStackOverflow = errors.New("call stack overflow")
+	// ErrCallStackUnderflow is the fault kind for returning when no function
+	// has been called.
+	ErrCallStackUnderflow = errors.New("call stack underflow")
+	// ErrOutOfBounds is the fault kind for accessing an address outside of
+	// the heap, or jumping to an address outside of the program.
+	ErrOutOfBounds = errors.New("memory address out of bounds")
+	// ErrDivisionByZero is the fault kind for a division or modulo by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrTruncatedInstruction is the fault kind for an instruction that is cut
+	// off by the end of the program.
+	ErrTruncatedInstruction = errors.New("unexpected end of program")
+	// ErrUnknownOpcode is the fault kind for an opcode that does not exist.
+	ErrUnknownOpcode = errors.New("unknown opcode")
+	// ErrHostCallHandlerNotSet is the fault kind for a host call without a
+	// host call handler.
+	ErrHostCallHandlerNotSet = errors.New("host call handler not set")
+)
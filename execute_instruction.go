@@ -0,0 +1,146 @@
+package vm
+
+func (v *VM) executeInstruction(
+	opcode Opcode,
+	instructionStart register,
+	instructionEnd register,
+) error {
+	switch opcode {
+	case OpcodeNop:
+		// noop
+
+	case OpcodePush:
+		return v.instructionPush(instructionStart, instructionEnd)
+
+	case OpcodePop:
+		return v.instructionPop(instructionStart, instructionEnd)
+
+	case OpcodeLoadImmediate:
+		return v.instructionLoadImmediate(instructionStart, instructionEnd)
+
+	case OpcodeLoadRegister:
+		return v.instructionLoadRegister(instructionStart, instructionEnd)
+
+	case OpcodeLoadMemory:
+		return v.instructionLoadMemory(instructionStart, instructionEnd)
+
+	case OpcodeStoreMemory:
+		return v.instructionStoreMemory(instructionStart, instructionEnd)
+
+	case OpcodeAdd:
+		return v.instructionAdd(instructionStart, instructionEnd)
+
+	case OpcodeSub:
+		return v.instructionSub(instructionStart, instructionEnd)
+
+	case OpcodeMul:
+		return v.instructionMul(instructionStart, instructionEnd)
+
+	case OpcodeDiv:
+		return v.instructionDiv(instructionStart, instructionEnd)
+
+	case OpcodeMod:
+		return v.instructionMod(instructionStart, instructionEnd)
+
+	case OpcodeAND:
+		return v.instructionAND(instructionStart, instructionEnd)
+
+	case OpcodeOR:
+		return v.instructionOR(instructionStart, instructionEnd)
+
+	case OpcodeXOR:
+		return v.instructionXOR(instructionStart, instructionEnd)
+
+	case OpcodeNOT:
+		return v.instructionNOT(instructionStart, instructionEnd)
+
+	case OpcodeShiftLeft:
+		return v.instructionShiftLeft(instructionStart, instructionEnd)
+
+	case OpcodeShiftRight:
+		return v.instructionShiftRight(instructionStart, instructionEnd)
+
+	case OpcodeShiftRightArithmetic:
+		return v.instructionShiftRightArithmetic(instructionStart, instructionEnd)
+
+	case OpcodeCMP:
+		return v.instructionCMP(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediate:
+		return v.instructionJmpImmediate(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfZero:
+		return v.instructionJmpImmediateIfZero(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfNotZero:
+		return v.instructionJmpImmediateIfNotZero(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfEqual:
+		return v.instructionJmpImmediateIfEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfNotEqual:
+		return v.instructionJmpImmediateIfNotEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfGreater:
+		return v.instructionJmpImmediateIfGreater(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfGreaterOrEqual:
+		return v.instructionJmpImmediateIfGreaterOrEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfLess:
+		return v.instructionJmpImmediateIfLess(instructionStart, instructionEnd)
+
+	case OpcodeJmpImmediateIfLessOrEqual:
+		return v.instructionJmpImmediateIfLessOrEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegister:
+		return v.instructionJmpRegister(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfZero:
+		return v.instructionJmpRegisterIfZero(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfNotZero:
+		return v.instructionJmpRegisterIfNotZero(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfEqual:
+		return v.instructionJmpRegisterIfEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfNotEqual:
+		return v.instructionJmpRegisterIfNotEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfGreater:
+		return v.instructionJmpRegisterIfGreater(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfGreaterOrEqual:
+		return v.instructionJmpRegisterIfGreaterOrEqual(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfLess:
+		return v.instructionJmpRegisterIfLess(instructionStart, instructionEnd)
+
+	case OpcodeJmpRegisterIfLessOrEqual:
+		return v.instructionJmpRegisterIfLessOrEqual(instructionStart, instructionEnd)
+
+	case OpcodeCallImmediate:
+		return v.instructionCallImmediate(instructionStart, instructionEnd)
+
+	case OpcodeCallRegister:
+		return v.instructionCallRegister(instructionStart, instructionEnd)
+
+	case OpcodeReturn:
+		return v.instructionReturn(instructionStart, instructionEnd)
+
+	case OpcodeHostCall:
+		return v.instructionHostCall(instructionStart, instructionEnd)
+
+	case OpcodeHalt:
+		return v.instructionHalt(instructionStart, instructionEnd)
+
+	case OpcodeHaltRegister:
+		return v.instructionHaltRegister(instructionStart, instructionEnd)
+
+	default:
+		return newFault(ErrUnknownOpcode)
+	}
+
+	return nil
+}
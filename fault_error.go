@@ -0,0 +1,100 @@
+package vm
+
+import (
+	"fmt"
+)
+
+// FaultError describes a fault that occurred while executing an instruction.
+// Use errors.Is with one of the Err* variables to check the kind of fault.
+type FaultError struct {
+	// The kind of fault.
+	// This is one of the Err* variables, or the error of the host call handler.
+	Err error
+	// The program counter at the start of the faulting instruction.
+	PC register
+	// The opcode of the faulting instruction.
+	Opcode Opcode
+	// The raw bytes of the faulting instruction.
+	Instruction []byte
+	// The faulting address, if HasAddress is true.
+	Address int64
+	// Whether the fault is caused by an address.
+	HasAddress bool
+	// The faulting register, if HasRegister is true.
+	Register register
+	// Whether the fault is caused by the value of a register.
+	HasRegister bool
+}
+
+// Error returns the error message, including the location of the fault.
+func (e *FaultError) Error() string {
+	msg := fmt.Sprintf("%s at pc %d (%s)", e.Err.Error(), e.PC, e.Opcode)
+
+	if e.HasRegister {
+		msg += fmt.Sprintf(", register r%d", e.Register)
+	}
+
+	if e.HasAddress {
+		msg += fmt.Sprintf(", address %d", e.Address)
+	}
+
+	return msg
+}
+
+// Unwrap returns the kind of fault.
+func (e *FaultError) Unwrap() error {
+	return e.Err
+}
+
+func newFault(err error) *FaultError {
+	return &FaultError{
+		Err:         err,
+		PC:          0,
+		Opcode:      OpcodeNop,
+		Instruction: nil,
+		Address:     0,
+		HasAddress:  false,
+		Register:    0,
+		HasRegister: false,
+	}
+}
+
+func (e *FaultError) withAddress(addr int64) *FaultError {
+	e.Address = addr
+	e.HasAddress = true
+
+	return e
+}
+
+func (e *FaultError) withRegister(reg register) *FaultError {
+	e.Register = reg
+	e.HasRegister = true
+
+	return e
+}
+
+// wrapFault adds the location of the faulting instruction to an error.
+func (v *VM) wrapFault(
+	err error,
+	opcode Opcode,
+	instructionStart register,
+	instructionEnd register,
+) *FaultError {
+	fault, isFault := err.(*FaultError)
+
+	if !isFault {
+		fault = newFault(err)
+	}
+
+	instructionEnd = max(instructionEnd, instructionStart+1)
+	instructionEnd = min(instructionEnd, v.programLen)
+
+	fault.PC = instructionStart
+	fault.Opcode = opcode
+	fault.Instruction = append(
+		[]byte{},
+		v.program[instructionStart:instructionEnd]...,
+	)
+
+	return fault
+}
@@ -0,0 +1,106 @@
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFaultError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		program  []byte
+		expected FaultError
+	}{
+		{
+			name: "load memory out of bounds",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 3, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				byte(OpcodeLoadMemory), 1, 3,
+			},
+			expected: FaultError{
+				Err:         ErrOutOfBounds,
+				PC:          10,
+				Opcode:      OpcodeLoadMemory,
+				Instruction: []byte{byte(OpcodeLoadMemory), 1, 3},
+				Address:     -1,
+				HasAddress:  true,
+				Register:    3,
+				HasRegister: true,
+			},
+		},
+		{
+			name: "division by zero",
+			program: []byte{
+				byte(OpcodeDiv), 0, 1, 2,
+			},
+			expected: FaultError{
+				Err:         ErrDivisionByZero,
+				PC:          0,
+				Opcode:      OpcodeDiv,
+				Instruction: []byte{byte(OpcodeDiv), 0, 1, 2},
+				Address:     0,
+				HasAddress:  false,
+				Register:    2,
+				HasRegister: true,
+			},
+		},
+		{
+			name: "truncated instruction",
+			program: []byte{
+				byte(OpcodeNop),
+				byte(OpcodeJmpImmediate), 0, 0,
+			},
+			expected: FaultError{
+				Err:         ErrTruncatedInstruction,
+				PC:          1,
+				Opcode:      OpcodeJmpImmediate,
+				Instruction: []byte{byte(OpcodeJmpImmediate), 0, 0},
+				Address:     0,
+				HasAddress:  false,
+				Register:    0,
+				HasRegister: false,
+			},
+		},
+		{
+			name: "unknown opcode",
+			program: []byte{
+				0xFF,
+			},
+			expected: FaultError{
+				Err:         ErrUnknownOpcode,
+				PC:          0,
+				Opcode:      0xFF,
+				Instruction: []byte{0xFF},
+				Address:     0,
+				HasAddress:  false,
+				Register:    0,
+				HasRegister: false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.program).Run()
+
+			var fault *FaultError
+
+			if !errors.As(err, &fault) {
+				t.Fatalf("expected a *FaultError, got \"%v\"", err)
+			}
+
+			if !reflect.DeepEqual(*fault, test.expected) {
+				t.Fatalf("expected fault to be %+v, got %+v", test.expected, *fault)
+			}
+
+			if !errors.Is(err, test.expected.Err) {
+				t.Fatalf("expected error to wrap \"%v\"", test.expected.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package vm
+
+// Fuel returns the amount of fuel that is left.
+// When fuel metering is disabled, this always returns zero.
+func (v *VM) Fuel() uint64 {
+	return v.fuel
+}
+
+// AddFuel refuels the VM, so that execution can be resumed after running out.
+// When fuel metering is disabled, this does nothing.
+func (v *VM) AddFuel(amount uint64) {
+	if !v.fuelEnabled {
+		return
+	}
+
+	v.fuel += amount
+}
+
+// ConsumeFuel takes an additional amount of fuel.
+// This is meant to be called from a HostCallHandler,
+// to charge for the work that the host does.
+//
+// When there is not enough fuel left, no fuel is taken and ErrOutOfFuel
+// is returned. If the host call handler returns this error,
+// the host call is rolled back and retried when execution is resumed.
+func (v *VM) ConsumeFuel(amount uint64) error {
+	if !v.fuelEnabled {
+		return nil
+	}
+
+	if amount > v.fuel {
+		return ErrOutOfFuel
+	}
+
+	v.fuel -= amount
+
+	return nil
+}
+
+func (v *VM) getFuelCost(opcode Opcode) uint64 {
+	cost, hasCost := v.fuelCosts[opcode]
+
+	if !hasCost {
+		return DefaultFuelCost
+	}
+
+	return cost
+}
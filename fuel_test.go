@@ -0,0 +1,141 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFuel(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodeAdd), 0, 0, 0,
+		byte(OpcodeAdd), 0, 0, 0,
+	}
+
+	vm := New(program, WithFuel(4, map[Opcode]uint64{OpcodeAdd: 2}))
+	_, err := vm.Run()
+
+	if !errors.Is(err, ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfFuel, err)
+	}
+
+	if vm.PC() != 14 || vm.Fuel() != 1 || vm.registers[0] != 2 {
+		t.Fatalf(
+			"expected to stop before the second add, got pc %d, fuel %d, r0 %d",
+			vm.PC(),
+			vm.Fuel(),
+			vm.registers[0],
+		)
+	}
+
+	vm.AddFuel(3)
+
+	if _, err = vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.Fuel() != 2 || vm.registers[0] != 4 {
+		t.Fatalf("expected fuel 2 and r0 4, got fuel %d and r0 %d", vm.Fuel(), vm.registers[0])
+	}
+}
+
+func TestFuelHostCall(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 0, 1, 0,
+	}
+
+	var vm *VM
+
+	numCalls := 0
+
+	vm = New(
+		program,
+		WithFuel(5, nil),
+		WithHostCallHandler(func(
+			_ int64,
+			_ register,
+			_ register,
+			_ [NumRegisters]int64,
+		) (int64, error) {
+			numCalls++
+
+			return 42, vm.ConsumeFuel(5)
+		}),
+	)
+
+	_, err := vm.Run()
+
+	if !errors.Is(err, ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfFuel, err)
+	}
+
+	if vm.PC() != 0 || vm.Fuel() != 5 {
+		t.Fatalf("expected the host call to be rolled back, got pc %d, fuel %d", vm.PC(), vm.Fuel())
+	}
+
+	vm.AddFuel(1)
+
+	if _, err = vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if numCalls != 2 || vm.Fuel() != 0 || vm.registers[1] != 42 {
+		t.Fatalf(
+			"expected the host call to be retried, got %d calls, fuel %d, r1 %d",
+			numCalls,
+			vm.Fuel(),
+			vm.registers[1],
+		)
+	}
+}
+
+func TestFuelHostCallPartiallyConsumed(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 0, 1, 0,
+	}
+
+	var vm *VM
+
+	vm = New(
+		program,
+		WithFuel(6, nil),
+		WithHostCallHandler(func(
+			_ int64,
+			_ register,
+			_ register,
+			_ [NumRegisters]int64,
+		) (int64, error) {
+			if err := vm.ConsumeFuel(3); err != nil {
+				return 0, err
+			}
+
+			return 42, vm.ConsumeFuel(5)
+		}),
+	)
+
+	_, err := vm.Run()
+
+	if !errors.Is(err, ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfFuel, err)
+	}
+
+	if vm.PC() != 0 || vm.Fuel() != 6 {
+		t.Fatalf("expected all fuel of the host call to be refunded, got pc %d, fuel %d", vm.PC(), vm.Fuel())
+	}
+
+	vm.AddFuel(3)
+
+	if _, err = vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.Fuel() != 0 || vm.registers[1] != 42 {
+		t.Fatalf("expected the host call to be retried, got fuel %d, r1 %d", vm.Fuel(), vm.registers[1])
+	}
+}
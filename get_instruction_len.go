@@ -45,6 +45,7 @@ var instructionLengths = map[Opcode]uint64{
 	OpcodeReturn:                       1,
 	OpcodeHostCall:                     11,
 	OpcodeHalt:                         1,
+	OpcodeHaltRegister:                 2,
 }
 
 // GetInstructionLen returns the length of the provided instruction.
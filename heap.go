@@ -0,0 +1,60 @@
+package vm
+
+// heapPageSize is the number of values in a single page of the heap.
+// Please note that this has to be a power of two.
+const heapPageSize = 1024
+
+// heapPageMask is the bitmask to get the offset of an address within a page.
+const heapPageMask = heapPageSize - 1
+
+// heapPage is a page of the heap.
+// Pages are allocated when they are first written to,
+// and may be shared between cloned VMs until one of them writes to it.
+type heapPage [heapPageSize]int64
+
+// loadHeap returns the value at an address that is within the heap bounds.
+func (v *VM) loadHeap(addr uint64) int64 {
+	page := v.heapPages[addr/heapPageSize]
+
+	if page == nil {
+		return 0
+	}
+
+	return page[addr&heapPageMask]
+}
+
+// storeHeap stores a value at an address that is within the heap bounds.
+func (v *VM) storeHeap(addr uint64, val int64) {
+	pageIndex := addr / heapPageSize
+	page := v.heapPages[pageIndex]
+
+	if page == nil {
+		if val == 0 {
+			return
+		}
+
+		page = &heapPage{}
+		v.heapPages[pageIndex] = page
+	} else if v.heapPagesShared[pageIndex] {
+		pageCopy := *page
+		page = &pageCopy
+		v.heapPages[pageIndex] = page
+		v.heapPagesShared[pageIndex] = false
+	}
+
+	page[addr&heapPageMask] = val
+}
+
+// allocateHeap creates an empty heap of heapSize values.
+func (v *VM) allocateHeap() {
+	numPages := (v.heapSize + heapPageSize - 1) / heapPageSize
+
+	v.heapPages = make([]*heapPage, numPages)
+	v.heapPagesShared = make([]bool, numPages)
+}
+
+// clearHeap sets all values in the heap back to zero.
+func (v *VM) clearHeap() {
+	clear(v.heapPages)
+	clear(v.heapPagesShared)
+}
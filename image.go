@@ -0,0 +1,211 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// imageMagic is the magic number at the start of every image.
+var imageMagic = []byte("VEEMIMG\x00")
+
+// imageVersion is the version of the image format.
+const imageVersion uint16 = 1
+
+// ISAVersion is the version of the instruction set that the VM implements.
+// Images that need a newer instruction set cannot be loaded.
+const ISAVersion uint16 = 1
+
+// ImageFeatures defines a set of optional features that an image needs.
+// No features have been defined yet, so images that have any of them set
+// cannot be loaded.
+type ImageFeatures uint64
+
+// supportedImageFeatures are the features that the VM implements.
+const supportedImageFeatures ImageFeatures = 0
+
+// SectionKind defines the contents of a section of an image.
+type SectionKind uint16
+
+const (
+	// SectionCode contains the bytecode of the program.
+	SectionCode SectionKind = iota + 1
+	// SectionData contains the initialized heap values,
+	// as runs of consecutive values.
+	SectionData
+	// SectionSymbols contains a symbol table, in the format of
+	// SymbolTable.WriteTo.
+	SectionSymbols
+	// SectionImports contains the names of the host functions that the
+	// program calls, one per line. The index of a name is the index that
+	// OpcodeHostCall calls it with.
+	SectionImports
+	// SectionExports contains the functions that the host may call,
+	// in the format of SymbolTable.WriteTo.
+	SectionExports
+	// SectionDebug contains a source map, in the format of SourceMap.WriteTo.
+	SectionDebug
+)
+
+// imageHeaderLen is the size of the image header:
+// the magic number, the format and ISA versions, the features,
+// and the number of sections.
+const imageHeaderLen = 8 + 2 + 2 + 8 + 2
+
+// imageSectionEntryLen is the size of an entry in the section table:
+// the kind, the offset and the length of the section.
+const imageSectionEntryLen = 2 + 8 + 8
+
+// DataSegment defines a range of initialized heap values.
+type DataSegment struct {
+	// The heap address of the first value.
+	Address uint64
+	// The values, which are stored at consecutive heap addresses.
+	Values []int64
+}
+
+// Image defines an executable program, along with the metadata to run and
+// debug it.
+//
+// An image starts with a magic number, the format version, the version of
+// the instruction set it needs, the features it needs and a section table.
+// All values are big-endian.
+// Addresses in the image are relative to the start of the code section.
+type Image struct {
+	// The version of the instruction set that the code needs.
+	ISAVersion uint16
+	// The optional features that the image needs.
+	Features ImageFeatures
+	// The bytecode of the program.
+	Code []byte
+	// The initialized heap values.
+	Data []DataSegment
+	// The symbols of the program. This may be nil.
+	Symbols *SymbolTable
+	// The names of the host functions that the program calls,
+	// by host call index.
+	Imports []string
+	// The functions that the host may call. This may be nil.
+	Exports *SymbolTable
+	// The source map of the program. This may be nil.
+	SourceMap *SourceMap
+}
+
+// NewImage creates a new image with code, for the current instruction set.
+func NewImage(code []byte) *Image {
+	return &Image{
+		ISAVersion: ISAVersion,
+		Features:   0,
+		Code:       code,
+		Data:       nil,
+		Symbols:    nil,
+		Imports:    nil,
+		Exports:    nil,
+		SourceMap:  nil,
+	}
+}
+
+// IsImage returns whether data starts with the magic number of an image.
+func IsImage(data []byte) bool {
+	return bytes.HasPrefix(data, imageMagic)
+}
+
+// NewVM creates a new VM that runs the code of the image,
+// with the initialized data stored in the heap.
+func (img *Image) NewVM(options ...Option) *VM {
+	imageOptions := make([]Option, 0, len(img.Data)+len(options))
+
+	for _, segment := range img.Data {
+		imageOptions = append(imageOptions, WithHeapImage(segment.Address, segment.Values))
+	}
+
+	return New(img.Code, append(imageOptions, options...)...)
+}
+
+// MarshalBinary encodes the image, which can be loaded with LoadImage.
+// Sections without content are left out, except for the code section.
+//
+// The file paths in the source map must not be empty, and must not contain
+// line breaks, or whitespace at the start, at the end or more than once
+// in a row, since they could not be read back.
+func (img *Image) MarshalBinary() ([]byte, error) {
+	for _, mapping := range img.SourceMap.Mappings() {
+		if mapping.File == "" || strings.Join(strings.Fields(mapping.File), " ") != mapping.File {
+			return nil, fmt.Errorf(
+				"%w: source mapping at address 0x%04x has file %q, which cannot be stored",
+				ErrInvalidImage,
+				mapping.Address,
+				mapping.File,
+			)
+		}
+	}
+
+	type section struct {
+		kind SectionKind
+		data []byte
+	}
+
+	sections := []section{{kind: SectionCode, data: img.Code}}
+
+	if len(img.Data) != 0 {
+		sections = append(sections, section{kind: SectionData, data: appendDataSegments(nil, img.Data)})
+	}
+
+	if len(img.Symbols.Symbols()) != 0 {
+		var buf bytes.Buffer
+		_, _ = img.Symbols.WriteTo(&buf)
+		sections = append(sections, section{kind: SectionSymbols, data: buf.Bytes()})
+	}
+
+	if len(img.Imports) != 0 {
+		imports := strings.Join(img.Imports, "\n") + "\n"
+		sections = append(sections, section{kind: SectionImports, data: []byte(imports)})
+	}
+
+	if len(img.Exports.Symbols()) != 0 {
+		var buf bytes.Buffer
+		_, _ = img.Exports.WriteTo(&buf)
+		sections = append(sections, section{kind: SectionExports, data: buf.Bytes()})
+	}
+
+	if len(img.SourceMap.Mappings()) != 0 {
+		var buf bytes.Buffer
+		_, _ = img.SourceMap.WriteTo(&buf)
+		sections = append(sections, section{kind: SectionDebug, data: buf.Bytes()})
+	}
+
+	data := slices.Clone(imageMagic)
+	data = binary.BigEndian.AppendUint16(data, imageVersion)
+	data = binary.BigEndian.AppendUint16(data, img.ISAVersion)
+	data = binary.BigEndian.AppendUint64(data, uint64(img.Features))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(sections))) // #nosec: G115
+
+	offset := uint64(imageHeaderLen + len(sections)*imageSectionEntryLen)
+
+	for _, s := range sections {
+		data = binary.BigEndian.AppendUint16(data, uint16(s.kind))
+		data = binary.BigEndian.AppendUint64(data, offset)
+		data = binary.BigEndian.AppendUint64(data, uint64(len(s.data)))
+		offset += uint64(len(s.data))
+	}
+
+	for _, s := range sections {
+		data = append(data, s.data...)
+	}
+
+	return data, nil
+}
+
+// appendDataSegments appends the data segments as heap runs,
+// prefixed by the number of runs.
+func appendDataSegments(data []byte, segments []DataSegment) []byte {
+	data = binary.BigEndian.AppendUint64(data, uint64(len(segments)))
+
+	for _, segment := range segments {
+		data = appendHeapRun(data, segment.Address, segment.Values)
+	}
+
+	return data
+}
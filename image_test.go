@@ -0,0 +1,317 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestImage() *Image {
+	img := NewImage([]byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 42,
+		byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 20,
+		byte(OpcodeHalt),
+		byte(OpcodeHaltRegister), 0,
+	})
+
+	img.Data = []DataSegment{{Address: 100, Values: []int64{1, -2, 3}}}
+	img.Symbols = NewSymbolTable([]Symbol{{Name: "main", Address: 0}, {Name: "f", Address: 20}})
+	img.Imports = []string{"print", "exit"}
+	img.Exports = NewSymbolTable([]Symbol{{Name: "f", Address: 20}})
+	img.SourceMap = NewSourceMap([]SourceMapping{{Address: 0, File: "main.s", Line: 1}})
+
+	return img
+}
+
+func TestImage(t *testing.T) {
+	t.Parallel()
+
+	img := newTestImage()
+	data, err := img.MarshalBinary()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !IsImage(data) || IsImage(img.Code) {
+		t.Fatalf("expected only the encoded image to be detected as an image")
+	}
+
+	loaded, err := LoadImage(data)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(loaded, img) {
+		t.Fatalf("expected %+v, got %+v", img, loaded)
+	}
+
+	v := loaded.NewVM()
+
+	if heap, _ := v.ReadHeap(100, 3); !slices.Equal(heap, []int64{1, -2, 3}) {
+		t.Fatalf("expected the data to be stored in the heap, got %v", heap)
+	}
+
+	result, err := v.Run()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if result.ExitStatus != 42 {
+		t.Fatalf("expected exit status 42, got %d", result.ExitStatus)
+	}
+
+	data, _ = NewImage(nil).MarshalBinary()
+	loaded, err = LoadImage(data)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(loaded.Code) != 0 || loaded.Data != nil || loaded.Imports != nil || loaded.Symbols != nil {
+		t.Fatalf("expected an empty image, got %+v", loaded)
+	}
+}
+
+func TestImageSourceMap(t *testing.T) {
+	t.Parallel()
+
+	img := NewImage([]byte{byte(OpcodeNop), byte(OpcodeHalt)})
+	img.SourceMap = NewSourceMap([]SourceMapping{
+		{Address: 0, File: "src/main.s", Line: 3},
+		{Address: 1, File: "my programs/lib.s", Line: 10},
+	})
+
+	data, err := img.MarshalBinary()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	loaded, err := LoadImage(data)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(loaded.SourceMap, img.SourceMap) {
+		t.Fatalf("expected %v, got %v", img.SourceMap.Mappings(), loaded.SourceMap.Mappings())
+	}
+
+	for _, file := range []string{"", " main.s", "main.s ", "two  spaces.s", "line\nbreak.s"} {
+		img.SourceMap = NewSourceMap([]SourceMapping{{Address: 0, File: file, Line: 1}})
+
+		if _, err := img.MarshalBinary(); !errors.Is(err, ErrInvalidImage) {
+			t.Fatalf("expected error to be \"%v\" for file %q, got \"%v\"", ErrInvalidImage, file, err)
+		}
+	}
+}
+
+func TestReadImportsEmpty(t *testing.T) {
+	t.Parallel()
+
+	imports, err := readImports(nil)
+
+	if err != nil || imports != nil {
+		t.Fatalf("expected no imports and no error, got %v and %v", imports, err)
+	}
+}
+
+func TestLoadImageErr(t *testing.T) {
+	t.Parallel()
+
+	// The offset of the first entry of the section table.
+	const table = imageHeaderLen
+
+	tests := []struct {
+		name     string
+		modify   func(img *Image, data []byte) []byte
+		expected string
+	}{
+		{
+			name:     "missing magic number",
+			modify:   func(_ *Image, data []byte) []byte { return data[:4] },
+			expected: "invalid image: missing magic number",
+		},
+		{
+			name: "unsupported format version",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint16(data[8:], 2)
+
+				return data
+			},
+			expected: "invalid image: unsupported format version 2",
+		},
+		{
+			name: "unsupported instruction set version",
+			modify: func(img *Image, _ []byte) []byte {
+				img.ISAVersion = ISAVersion + 1
+
+				return nil
+			},
+			expected: "invalid image: unsupported instruction set version 2",
+		},
+		{
+			name: "unsupported features",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Features = 0x10
+
+				return nil
+			},
+			expected: "invalid image: unsupported features 0x10",
+		},
+		{
+			name:     "truncated section table",
+			modify:   func(_ *Image, data []byte) []byte { return data[:table+4] },
+			expected: "invalid image: unexpected end of image",
+		},
+		{
+			name: "unknown section kind",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint16(data[table:], 99)
+
+				return data
+			},
+			expected: "invalid image: unknown section kind 99",
+		},
+		{
+			name: "duplicate section kind",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint16(data[table+imageSectionEntryLen:], uint16(SectionCode))
+
+				return data
+			},
+			expected: "invalid image: duplicate section kind 1",
+		},
+		{
+			name: "missing code section",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint16(data[20:], 0)
+
+				return data
+			},
+			expected: "invalid image: missing code section",
+		},
+		{
+			name: "section out of bounds",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint64(data[table+10:], 1<<63)
+
+				return data
+			},
+			expected: "invalid image: section kind 1 out of bounds",
+		},
+		{
+			name: "section inside the section table",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint64(data[table+2:], 0)
+
+				return data
+			},
+			expected: "invalid image: section kind 1 out of bounds",
+		},
+		{
+			name: "overlapping sections",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint64(data[table+10:], 30)
+
+				return data
+			},
+			expected: "invalid image: overlapping sections",
+		},
+		{
+			name: "truncated instruction",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Code = img.Code[:len(img.Code)-1]
+				img.Exports = nil
+
+				return nil
+			},
+			expected: "invalid image: unexpected end of program at address 0x0014",
+		},
+		{
+			name: "unknown opcode",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Code = append(img.Code, 0xFF)
+
+				return nil
+			},
+			expected: "invalid image: unknown opcode at address 0x0016",
+		},
+		{
+			name: "truncated data",
+			modify: func(_ *Image, data []byte) []byte {
+				binary.BigEndian.PutUint64(data[table+imageSectionEntryLen+10:], 8)
+
+				return data
+			},
+			expected: "invalid image: unexpected end of data section",
+		},
+		{
+			name: "invalid symbols",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Symbols = NewSymbolTable([]Symbol{{Name: "two words", Address: 0}})
+
+				return nil
+			},
+			expected: "invalid image: section kind 3: line 1: expected an address and a name",
+		},
+		{
+			name: "invalid import",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Imports = []string{"print", "print"}
+
+				return nil
+			},
+			expected: "invalid image: section kind 4: line 2: duplicate import \"print\"",
+		},
+		{
+			name: "export outside of an instruction",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Exports = NewSymbolTable([]Symbol{{Name: "f", Address: 21}})
+
+				return nil
+			},
+			expected: "invalid image: export \"f\" does not point to an instruction",
+		},
+		{
+			name: "duplicate export",
+			modify: func(img *Image, _ []byte) []byte {
+				img.Exports = NewSymbolTable([]Symbol{{Name: "f", Address: 0}, {Name: "f", Address: 20}})
+
+				return nil
+			},
+			expected: "invalid image: duplicate export \"f\"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			img := newTestImage()
+			data, _ := img.MarshalBinary()
+			data = test.modify(img, bytes.Clone(data))
+
+			if data == nil {
+				data, _ = img.MarshalBinary()
+			}
+
+			_, err := LoadImage(data)
+
+			if !errors.Is(err, ErrInvalidImage) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrInvalidImage, err)
+			}
+
+			if !strings.HasPrefix(err.Error(), test.expected) {
+				t.Fatalf("expected error message to start with \"%s\", got \"%s\"", test.expected, err.Error())
+			}
+		})
+	}
+}
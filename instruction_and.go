@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionAND(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	dest := register(v.program[instructionStart+1]) & NumRegistersMask
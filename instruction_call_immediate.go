@@ -2,7 +2,6 @@ package vm
 
 import (
 	"encoding/binary"
-	"errors"
 )
 
 func (v *VM) instructionCallImmediate(
@@ -10,26 +9,16 @@ func (v *VM) instructionCallImmediate(
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
-	if v.sp >= uint64(len(v.stack)) {
-		return errors.New("stack overflow")
-	}
-
-	returnAddr := int64(v.pc) // #nosec: G115
-	v.stack[v.sp] = returnAddr
-	v.sp++
-
 	addr := binary.BigEndian.Uint64(
 		v.program[instructionStart+1 : instructionEnd],
 	)
 
 	if addr >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).withAddress(int64(addr)) // #nosec: G115
 	}
 
-	v.pc = addr
-
-	return nil
+	return v.pushCallFrame(instructionStart, addr)
 }
@@ -1,33 +1,21 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionCallRegister(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
-	}
-
-	if v.sp >= uint64(len(v.stack)) {
-		return errors.New("stack overflow")
+		return newFault(ErrTruncatedInstruction)
 	}
 
-	returnAddr := int64(v.pc) // #nosec: G115
-	v.stack[v.sp] = returnAddr
-	v.sp++
-
 	src1 := register(v.program[instructionStart+1]) & NumRegistersMask
 	addr := v.registers[src1]
 
 	if addr < 0 || uint64(addr) >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).
+			withRegister(src1).
+			withAddress(addr)
 	}
 
-	v.pc = register(addr)
-
-	return nil
+	return v.pushCallFrame(instructionStart, register(addr))
 }
@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionCMP(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	src1 := register(v.program[instructionStart+1]) & NumRegistersMask
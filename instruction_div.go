@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionDiv(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	dest := register(v.program[instructionStart+1]) & NumRegistersMask
@@ -17,7 +13,7 @@ func (v *VM) instructionDiv(
 	src2 := register(v.program[instructionStart+3]) & NumRegistersMask
 
 	if v.registers[src2] == 0 {
-		return errors.New("division by zero")
+		return newFault(ErrDivisionByZero).withRegister(src2)
 	}
 
 	v.registers[dest] = v.registers[src1] / v.registers[src2]
@@ -2,6 +2,8 @@ package vm
 
 func (v *VM) instructionHalt(_ register, _ register) error {
 	v.pc = v.programLen
+	v.halted = true
+	v.exitStatus = 0
 
 	return nil
 }
@@ -0,0 +1,18 @@
+package vm
+
+func (v *VM) instructionHaltRegister(
+	instructionStart register,
+	instructionEnd register,
+) error {
+	if instructionEnd > v.programLen {
+		return newFault(ErrTruncatedInstruction)
+	}
+
+	src1 := register(v.program[instructionStart+1]) & NumRegistersMask
+
+	v.pc = v.programLen
+	v.halted = true
+	v.exitStatus = v.registers[src1]
+
+	return nil
+}
@@ -2,7 +2,6 @@ package vm
 
 import (
 	"encoding/binary"
-	"errors"
 )
 
 func (v *VM) instructionHostCall(
@@ -10,7 +9,7 @@ func (v *VM) instructionHostCall(
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	funcIndex := int64(binary.BigEndian.Uint64(
@@ -21,7 +20,7 @@ func (v *VM) instructionHostCall(
 	numArgs := register(v.program[instructionStart+10]) & NumRegistersMask
 
 	if v.hostCallHandler == nil {
-		return errors.New("host call handler not set")
+		return newFault(ErrHostCallHandlerNotSet)
 	}
 
 	result, err := v.hostCallHandler(funcIndex, arg1Reg, numArgs, v.registers)
@@ -2,7 +2,6 @@ package vm
 
 import (
 	"encoding/binary"
-	"errors"
 )
 
 func (v *VM) instructionJmpImmediate(
@@ -10,7 +9,7 @@ func (v *VM) instructionJmpImmediate(
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	addr := binary.BigEndian.Uint64(
@@ -18,7 +17,7 @@ func (v *VM) instructionJmpImmediate(
 	)
 
 	if addr >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).withAddress(int64(addr)) // #nosec: G115
 	}
 
 	v.pc = addr
@@ -2,7 +2,6 @@ package vm
 
 import (
 	"encoding/binary"
-	"errors"
 )
 
 func (v *VM) instructionJmpImmediateIfZero(
@@ -10,7 +9,7 @@ func (v *VM) instructionJmpImmediateIfZero(
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	src1 := register(v.program[instructionStart+1]) & NumRegistersMask
@@ -20,7 +19,7 @@ func (v *VM) instructionJmpImmediateIfZero(
 	)
 
 	if addr >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).withAddress(int64(addr)) // #nosec: G115
 	}
 
 	if v.registers[src1] == 0 {
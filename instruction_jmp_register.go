@@ -1,22 +1,20 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionJmpRegister(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	src1 := register(v.program[instructionStart+1]) & NumRegistersMask
 	addr := v.registers[src1]
 
 	if addr < 0 || uint64(addr) >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).
+			withRegister(src1).
+			withAddress(addr)
 	}
 
 	v.pc = register(addr)
@@ -1,22 +1,20 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionJmpRegisterIfGreaterOrEqual(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	addrReg := register(v.program[instructionStart+1]) & NumRegistersMask
 	addr := v.registers[addrReg]
 
 	if addr < 0 || uint64(addr) >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).
+			withRegister(addrReg).
+			withAddress(addr)
 	}
 
 	if !v.flags.isNegative {
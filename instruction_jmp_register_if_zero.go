@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionJmpRegisterIfZero(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	checkReg := register(v.program[instructionStart+1]) & NumRegistersMask
@@ -19,7 +15,9 @@ func (v *VM) instructionJmpRegisterIfZero(
 		addr := v.registers[addrReg]
 
 		if addr < 0 || uint64(addr) >= v.programLen {
-			return errors.New("memory address out of bounds")
+			return newFault(ErrOutOfBounds).
+				withRegister(addrReg).
+				withAddress(addr)
 		}
 
 		v.pc = register(addr)
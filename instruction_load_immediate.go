@@ -2,7 +2,6 @@ package vm
 
 import (
 	"encoding/binary"
-	"errors"
 )
 
 func (v *VM) instructionLoadImmediate(
@@ -10,7 +9,7 @@ func (v *VM) instructionLoadImmediate(
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	dest := register(v.program[instructionStart+1]) & NumRegistersMask
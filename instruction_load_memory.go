@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionLoadMemory(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	dest := register(v.program[instructionStart+1]) & NumRegistersMask
@@ -17,11 +13,14 @@ func (v *VM) instructionLoadMemory(
 
 	addr := v.registers[addrReg]
 
-	if addr < 0 || uint64(addr) >= HeapSize {
-		return errors.New("memory address out of bounds")
+	if addr < 0 || uint64(addr) >= v.heapSize {
+		return newFault(ErrOutOfBounds).
+			withRegister(addrReg).
+			withAddress(addr)
 	}
 
-	v.registers[dest] = v.heap[addr]
+	v.registers[dest] = v.loadHeap(uint64(addr))
+	v.checkWatchpoint(instructionStart, uint64(addr), WatchRead)
 
 	v.flags.isZero = v.registers[dest] == 0
 	v.flags.isNegative = v.registers[dest] < 0
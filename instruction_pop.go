@@ -1,22 +1,18 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionPop(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	rawDest := register(v.program[instructionStart+1])
 	dest := rawDest & NumRegistersMask
 
 	if v.sp == 0 {
-		return errors.New("stack underflow")
+		return newFault(ErrStackUnderflow)
 	}
 
 	v.registers[dest] = v.stack[v.sp-1]
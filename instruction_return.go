@@ -1,21 +1,17 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionReturn(_ register, _ register) error {
-	if v.sp == 0 {
-		return errors.New("stack underflow")
+	if len(v.callStack) == 0 {
+		return newFault(ErrCallStackUnderflow)
 	}
 
-	returnAddr := register(v.stack[v.sp-1]) // #nosec: G115
-	v.sp--
+	returnAddr := v.callStack[len(v.callStack)-1].ReturnAddress
 
 	if returnAddr >= v.programLen {
-		return errors.New("memory address out of bounds")
+		return newFault(ErrOutOfBounds).withAddress(int64(returnAddr)) // #nosec: G115
 	}
 
+	v.callStack = v.callStack[:len(v.callStack)-1]
 	v.pc = returnAddr
 
 	return nil
@@ -1,15 +1,11 @@
 package vm
 
-import (
-	"errors"
-)
-
 func (v *VM) instructionStoreMemory(
 	instructionStart register,
 	instructionEnd register,
 ) error {
 	if instructionEnd > v.programLen {
-		return errors.New("unexpected end of program")
+		return newFault(ErrTruncatedInstruction)
 	}
 
 	srcReg := register(v.program[instructionStart+1]) & NumRegistersMask
@@ -17,11 +13,14 @@ func (v *VM) instructionStoreMemory(
 
 	addr := v.registers[addrReg]
 
-	if addr < 0 || uint64(addr) >= HeapSize {
-		return errors.New("memory address out of bounds")
+	if addr < 0 || uint64(addr) >= v.heapSize {
+		return newFault(ErrOutOfBounds).
+			withRegister(addrReg).
+			withAddress(addr)
 	}
 
-	v.heap[addr] = v.registers[srcReg]
+	v.storeHeap(uint64(addr), v.registers[srcReg])
+	v.checkWatchpoint(instructionStart, uint64(addr), WatchWrite)
 
 	return nil
 }
@@ -0,0 +1,243 @@
+package vm
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// imageSection defines an entry in the section table of an image.
+type imageSection struct {
+	kind   SectionKind
+	offset uint64
+	length uint64
+}
+
+// LoadImage decodes and validates an image that was encoded with
+// Image.MarshalBinary.
+//
+// The image must have a supported format version, instruction set version
+// and features, its sections must lie within the image without overlapping,
+// and every section must be well-formed.
+// The code must consist of complete, known instructions,
+// and every export must point to the start of one of them.
+func LoadImage(data []byte) (*Image, error) {
+	r := newBinaryReader(data, "image", ErrInvalidImage)
+
+	if !bytes.Equal(r.next(uint64(len(imageMagic))), imageMagic) {
+		return nil, fmt.Errorf("%w: missing magic number", ErrInvalidImage)
+	}
+
+	version := r.uint16()
+
+	if r.err == nil && version != imageVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidImage, version)
+	}
+
+	img := NewImage(nil)
+	img.ISAVersion = r.uint16()
+	img.Features = ImageFeatures(r.uint64())
+
+	if r.err == nil && (img.ISAVersion == 0 || img.ISAVersion > ISAVersion) {
+		return nil, fmt.Errorf("%w: unsupported instruction set version %d", ErrInvalidImage, img.ISAVersion)
+	}
+
+	if unsupported := img.Features &^ supportedImageFeatures; r.err == nil && unsupported != 0 {
+		return nil, fmt.Errorf("%w: unsupported features 0x%x", ErrInvalidImage, uint64(unsupported))
+	}
+
+	sections := readSectionTable(r)
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	err := validateSectionTable(sections, uint64(len(data)-len(r.data)), uint64(len(data)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sections {
+		err = img.readSection(s.kind, data[s.offset:s.offset+s.length])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = img.validateCode()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+func readSectionTable(r *binaryReader) []imageSection {
+	numSections := r.uint16()
+	sections := make([]imageSection, 0, numSections)
+
+	for range numSections {
+		sections = append(sections, imageSection{
+			kind:   SectionKind(r.uint16()),
+			offset: r.uint64(),
+			length: r.uint64(),
+		})
+	}
+
+	return sections
+}
+
+// validateSectionTable checks that the sections lie between the end of the
+// section table and the end of the image, without overlapping,
+// that their kinds are known, and that there is exactly one code section.
+func validateSectionTable(sections []imageSection, tableEnd uint64, imageLen uint64) error {
+	seen := map[SectionKind]bool{}
+
+	for _, s := range sections {
+		if s.kind < SectionCode || s.kind > SectionDebug {
+			return fmt.Errorf("%w: unknown section kind %d", ErrInvalidImage, s.kind)
+		}
+
+		if seen[s.kind] {
+			return fmt.Errorf("%w: duplicate section kind %d", ErrInvalidImage, s.kind)
+		}
+
+		if s.offset < tableEnd || s.offset > imageLen || s.length > imageLen-s.offset {
+			return fmt.Errorf("%w: section kind %d out of bounds", ErrInvalidImage, s.kind)
+		}
+
+		seen[s.kind] = true
+	}
+
+	if !seen[SectionCode] {
+		return fmt.Errorf("%w: missing code section", ErrInvalidImage)
+	}
+
+	sorted := slices.SortedFunc(slices.Values(sections), func(a, b imageSection) int {
+		return cmp.Compare(a.offset, b.offset)
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].offset+sorted[i-1].length > sorted[i].offset {
+			return fmt.Errorf("%w: overlapping sections", ErrInvalidImage)
+		}
+	}
+
+	return nil
+}
+
+func (img *Image) readSection(kind SectionKind, data []byte) error {
+	var err error
+
+	switch kind {
+	case SectionCode:
+		img.Code = bytes.Clone(data)
+
+	case SectionData:
+		img.Data, err = readDataSegments(data)
+
+	case SectionSymbols:
+		img.Symbols, err = ReadSymbolTable(bytes.NewReader(data))
+
+	case SectionImports:
+		img.Imports, err = readImports(data)
+
+	case SectionExports:
+		img.Exports, err = ReadSymbolTable(bytes.NewReader(data))
+
+	case SectionDebug:
+		img.SourceMap, err = ReadSourceMap(bytes.NewReader(data))
+	}
+
+	if errors.Is(err, ErrInvalidImage) {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: section kind %d: %w", ErrInvalidImage, kind, err)
+	}
+
+	return nil
+}
+
+func readDataSegments(data []byte) ([]DataSegment, error) {
+	r := newBinaryReader(data, "data section", ErrInvalidImage)
+	runs := r.heapRuns(math.MaxUint64)
+
+	if r.err == nil && len(r.data) != 0 {
+		r.fail("unexpected trailing data")
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	segments := make([]DataSegment, len(runs))
+
+	for i, run := range runs {
+		segments[i] = DataSegment{Address: run.addr, Values: run.values}
+	}
+
+	return segments, nil
+}
+
+func readImports(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	imports := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	for i, name := range imports {
+		if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+			return nil, fmt.Errorf("line %d: invalid import name %q", i+1, name)
+		}
+
+		if slices.Contains(imports[:i], name) {
+			return nil, fmt.Errorf("line %d: duplicate import %q", i+1, name)
+		}
+	}
+
+	return imports, nil
+}
+
+// validateCode checks that the code consists of complete, known instructions,
+// and that every export points to the start of one of them.
+func (img *Image) validateCode() error {
+	isInstruction := map[uint64]bool{}
+
+	for addr := uint64(0); addr < uint64(len(img.Code)); {
+		_, err := DecodeOperands(img.Code, addr)
+
+		if err != nil {
+			return fmt.Errorf("%w: %w at address 0x%04x", ErrInvalidImage, err, addr)
+		}
+
+		isInstruction[addr] = true
+		addr += GetInstructionLen(Opcode(img.Code[addr]))
+	}
+
+	names := map[string]bool{}
+
+	for _, export := range img.Exports.Symbols() {
+		if !isInstruction[export.Address] {
+			return fmt.Errorf("%w: export %q does not point to an instruction", ErrInvalidImage, export.Name)
+		}
+
+		if names[export.Name] {
+			return fmt.Errorf("%w: duplicate export %q", ErrInvalidImage, export.Name)
+		}
+
+		names[export.Name] = true
+	}
+
+	return nil
+}
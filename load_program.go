@@ -0,0 +1,45 @@
+package vm
+
+// LoadProgram replaces the bytecode of the VM,
+// and moves the program counter to the start of the new program.
+// Breakpoints are removed, since their addresses belong to the old program.
+// Other state is left untouched, so call Reset first to start with a clean VM.
+//
+// Together with Reset, this allows VMs to be recycled with a sync.Pool:
+//
+//	pool := sync.Pool{
+//		New: func() any {
+//			return vm.New(nil, vm.WithMagicHeader(header))
+//		},
+//	}
+//
+//	v := pool.Get().(*vm.VM)
+//	defer pool.Put(v)
+//
+//	v.Reset(false)
+//	err := v.LoadProgram(program)
+func (v *VM) LoadProgram(program []byte) error {
+	previousProgram := v.program
+
+	v.program = program
+	v.programLen = register(len(program))
+
+	err := v.validateMagicHeader()
+
+	if err != nil {
+		v.program = previousProgram
+		v.programLen = register(len(previousProgram))
+
+		return err
+	}
+
+	clear(v.breakpoints)
+
+	v.pc = register(len(v.magicHeader))
+	v.halted = false
+	v.skipBreakpoint = false
+	v.clearUndo()
+	v.exitStatus = 0
+
+	return nil
+}
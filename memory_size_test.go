@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemorySize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		program  []byte
+		options  []Option
+		expected error
+	}{
+		{
+			name: "store memory in small heap",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 15,
+				byte(OpcodeStoreMemory), 0, 0,
+			},
+			options:  []Option{WithHeapSize(16)},
+			expected: nil,
+		},
+		{
+			name: "store memory out of bounds of small heap",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 16,
+				byte(OpcodeStoreMemory), 0, 0,
+			},
+			options:  []Option{WithHeapSize(16)},
+			expected: ErrOutOfBounds,
+		},
+		{
+			name: "load memory in large heap",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0x0F, 0xFF, 0xFF,
+				byte(OpcodeLoadMemory), 0, 0,
+			},
+			options:  []Option{WithHeapSize(HeapSize * 16)},
+			expected: nil,
+		},
+		{
+			name: "push onto small stack",
+			program: []byte{
+				byte(OpcodePush), 0,
+				byte(OpcodePush), 0,
+			},
+			options:  []Option{WithStackSize(2)},
+			expected: nil,
+		},
+		{
+			name: "push stack overflow of small stack",
+			program: []byte{
+				byte(OpcodePush), 0,
+				byte(OpcodePush), 0,
+				byte(OpcodePush), 0,
+			},
+			options:  []Option{WithStackSize(2)},
+			expected: ErrStackOverflow,
+		},
+		{
+			name: "call immediate call stack overflow of small call stack",
+			program: []byte{
+				byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 0,
+			},
+			options:  []Option{WithCallStackSize(4)},
+			expected: ErrCallStackOverflow,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.program, test.options...).Run()
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+		})
+	}
+}
@@ -100,4 +100,6 @@ const (
 
 	// OpcodeHalt stops execution of the VM.
 	OpcodeHalt
+	// OpcodeHaltRegister stops execution of the VM, with the exit status in a register.
+	OpcodeHaltRegister
 )
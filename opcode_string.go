@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+)
+
+var opcodeNames = map[Opcode]string{
+	OpcodeNop:                          "Nop",
+	OpcodePush:                         "Push",
+	OpcodePop:                          "Pop",
+	OpcodeLoadImmediate:                "LoadImmediate",
+	OpcodeLoadRegister:                 "LoadRegister",
+	OpcodeLoadMemory:                   "LoadMemory",
+	OpcodeStoreMemory:                  "StoreMemory",
+	OpcodeAdd:                          "Add",
+	OpcodeSub:                          "Sub",
+	OpcodeMul:                          "Mul",
+	OpcodeDiv:                          "Div",
+	OpcodeMod:                          "Mod",
+	OpcodeAND:                          "AND",
+	OpcodeOR:                           "OR",
+	OpcodeXOR:                          "XOR",
+	OpcodeNOT:                          "NOT",
+	OpcodeShiftLeft:                    "ShiftLeft",
+	OpcodeShiftRight:                   "ShiftRight",
+	OpcodeShiftRightArithmetic:         "ShiftRightArithmetic",
+	OpcodeCMP:                          "CMP",
+	OpcodeJmpImmediate:                 "JmpImmediate",
+	OpcodeJmpImmediateIfZero:           "JmpImmediateIfZero",
+	OpcodeJmpImmediateIfNotZero:        "JmpImmediateIfNotZero",
+	OpcodeJmpImmediateIfEqual:          "JmpImmediateIfEqual",
+	OpcodeJmpImmediateIfNotEqual:       "JmpImmediateIfNotEqual",
+	OpcodeJmpImmediateIfGreater:        "JmpImmediateIfGreater",
+	OpcodeJmpImmediateIfGreaterOrEqual: "JmpImmediateIfGreaterOrEqual",
+	OpcodeJmpImmediateIfLess:           "JmpImmediateIfLess",
+	OpcodeJmpImmediateIfLessOrEqual:    "JmpImmediateIfLessOrEqual",
+	OpcodeJmpRegister:                  "JmpRegister",
+	OpcodeJmpRegisterIfZero:            "JmpRegisterIfZero",
+	OpcodeJmpRegisterIfNotZero:         "JmpRegisterIfNotZero",
+	OpcodeJmpRegisterIfEqual:           "JmpRegisterIfEqual",
+	OpcodeJmpRegisterIfNotEqual:        "JmpRegisterIfNotEqual",
+	OpcodeJmpRegisterIfGreater:         "JmpRegisterIfGreater",
+	OpcodeJmpRegisterIfGreaterOrEqual:  "JmpRegisterIfGreaterOrEqual",
+	OpcodeJmpRegisterIfLess:            "JmpRegisterIfLess",
+	OpcodeJmpRegisterIfLessOrEqual:     "JmpRegisterIfLessOrEqual",
+	OpcodeCallImmediate:                "CallImmediate",
+	OpcodeCallRegister:                 "CallRegister",
+	OpcodeReturn:                       "Return",
+	OpcodeHostCall:                     "HostCall",
+	OpcodeHalt:                         "Halt",
+	OpcodeHaltRegister:                 "HaltRegister",
+}
+
+// opcodesByName maps the lowercase mnemonics to their opcodes.
+var opcodesByName = func() map[string]Opcode {
+	opcodes := make(map[string]Opcode, len(opcodeNames))
+
+	for opcode, name := range opcodeNames {
+		opcodes[strings.ToLower(name)] = opcode
+	}
+
+	return opcodes
+}()
+
+// ParseOpcode returns the opcode with a mnemonic, ignoring case,
+// so "ADD", "Add" and "add" all return OpcodeAdd.
+func ParseOpcode(name string) (Opcode, bool) {
+	opcode, hasOpcode := opcodesByName[strings.ToLower(name)]
+
+	return opcode, hasOpcode
+}
+
+// String returns the mnemonic of the opcode.
+func (o Opcode) String() string {
+	name, hasName := opcodeNames[o]
+
+	if !hasName {
+		return fmt.Sprintf("Opcode(0x%02X)", byte(o))
+	}
+
+	return name
+}
@@ -0,0 +1,40 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpcodeString(t *testing.T) {
+	t.Parallel()
+
+	for opcode := range instructionLengths {
+		if _, hasName := opcodeNames[opcode]; !hasName {
+			t.Fatalf("expected opcode %d to have a name", byte(opcode))
+		}
+	}
+
+	if OpcodeJmpImmediateIfLess.String() != "JmpImmediateIfLess" {
+		t.Fatalf("expected \"JmpImmediateIfLess\", got \"%s\"", OpcodeJmpImmediateIfLess.String())
+	}
+
+	if Opcode(0xFF).String() != "Opcode(0xFF)" {
+		t.Fatalf("expected \"Opcode(0xFF)\", got \"%s\"", Opcode(0xFF).String())
+	}
+}
+
+func TestParseOpcode(t *testing.T) {
+	t.Parallel()
+
+	for opcode, name := range opcodeNames {
+		for _, s := range []string{name, strings.ToUpper(name), strings.ToLower(name)} {
+			if parsed, hasOpcode := ParseOpcode(s); !hasOpcode || parsed != opcode {
+				t.Fatalf("expected \"%s\" to be parsed as %s, got %s", s, name, parsed)
+			}
+		}
+	}
+
+	if _, hasOpcode := ParseOpcode("Jump"); hasOpcode {
+		t.Fatalf("expected \"Jump\" not to be an opcode")
+	}
+}
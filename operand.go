@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"encoding/binary"
+)
+
+// OperandKind defines how an operand of an instruction is encoded.
+type OperandKind byte
+
+const (
+	// OperandRegister is a single byte with the index of a register.
+	OperandRegister OperandKind = iota
+	// OperandImmediate is an 8-byte big-endian signed value.
+	OperandImmediate
+	// OperandAddress is an 8-byte big-endian address in the program.
+	OperandAddress
+	// OperandCount is a single byte with a number of registers.
+	OperandCount
+)
+
+// Operand defines a decoded operand of an instruction.
+type Operand struct {
+	// How the operand is encoded.
+	Kind OperandKind
+	// The value of the operand.
+	Value int64
+}
+
+var operandKinds = map[Opcode][]OperandKind{
+	OpcodeNop:                          {},
+	OpcodePush:                         {OperandRegister},
+	OpcodePop:                          {OperandRegister},
+	OpcodeLoadImmediate:                {OperandRegister, OperandImmediate},
+	OpcodeLoadRegister:                 {OperandRegister, OperandRegister},
+	OpcodeLoadMemory:                   {OperandRegister, OperandRegister},
+	OpcodeStoreMemory:                  {OperandRegister, OperandRegister},
+	OpcodeAdd:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeSub:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeMul:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeDiv:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeMod:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeAND:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeOR:                           {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeXOR:                          {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeNOT:                          {OperandRegister, OperandRegister},
+	OpcodeShiftLeft:                    {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeShiftRight:                   {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeShiftRightArithmetic:         {OperandRegister, OperandRegister, OperandRegister},
+	OpcodeCMP:                          {OperandRegister, OperandRegister},
+	OpcodeJmpImmediate:                 {OperandAddress},
+	OpcodeJmpImmediateIfZero:           {OperandRegister, OperandAddress},
+	OpcodeJmpImmediateIfNotZero:        {OperandRegister, OperandAddress},
+	OpcodeJmpImmediateIfEqual:          {OperandAddress},
+	OpcodeJmpImmediateIfNotEqual:       {OperandAddress},
+	OpcodeJmpImmediateIfGreater:        {OperandAddress},
+	OpcodeJmpImmediateIfGreaterOrEqual: {OperandAddress},
+	OpcodeJmpImmediateIfLess:           {OperandAddress},
+	OpcodeJmpImmediateIfLessOrEqual:    {OperandAddress},
+	OpcodeJmpRegister:                  {OperandRegister},
+	OpcodeJmpRegisterIfZero:            {OperandRegister, OperandRegister},
+	OpcodeJmpRegisterIfNotZero:         {OperandRegister, OperandRegister},
+	OpcodeJmpRegisterIfEqual:           {OperandRegister},
+	OpcodeJmpRegisterIfNotEqual:        {OperandRegister},
+	OpcodeJmpRegisterIfGreater:         {OperandRegister},
+	OpcodeJmpRegisterIfGreaterOrEqual:  {OperandRegister},
+	OpcodeJmpRegisterIfLess:            {OperandRegister},
+	OpcodeJmpRegisterIfLessOrEqual:     {OperandRegister},
+	OpcodeCallImmediate:                {OperandAddress},
+	OpcodeCallRegister:                 {OperandRegister},
+	OpcodeReturn:                       {},
+	OpcodeHostCall:                     {OperandImmediate, OperandRegister, OperandCount},
+	OpcodeHalt:                         {},
+	OpcodeHaltRegister:                 {OperandRegister},
+}
+
+// GetOperandKinds returns the kinds of the operands of the provided instruction,
+// in the order in which they are encoded.
+func GetOperandKinds(opcode Opcode) []OperandKind {
+	kinds, hasKinds := operandKinds[opcode]
+
+	if !hasKinds {
+		return nil
+	}
+
+	return kinds
+}
+
+// Size returns the number of bytes that an operand of this kind takes up.
+func (k OperandKind) Size() uint64 {
+	if k == OperandImmediate || k == OperandAddress {
+		return 8
+	}
+
+	return 1
+}
+
+// DecodeOperands decodes the operands of the instruction that starts at
+// addr in the program.
+// Register and count operands are masked with NumRegistersMask,
+// like the VM does when it executes the instruction.
+func DecodeOperands(program []byte, addr uint64) ([]Operand, error) {
+	if addr >= uint64(len(program)) {
+		return nil, ErrOutOfBounds
+	}
+
+	opcode := Opcode(program[addr])
+	kinds, hasKinds := operandKinds[opcode]
+
+	if !hasKinds {
+		return nil, ErrUnknownOpcode
+	}
+
+	if addr+GetInstructionLen(opcode) > uint64(len(program)) {
+		return nil, ErrTruncatedInstruction
+	}
+
+	operands := make([]Operand, len(kinds))
+	offset := addr + 1
+
+	for i, kind := range kinds {
+		operands[i] = Operand{Kind: kind, Value: decodeOperand(program[offset:], kind)}
+		offset += kind.Size()
+	}
+
+	return operands, nil
+}
+
+func decodeOperand(b []byte, kind OperandKind) int64 {
+	switch kind {
+	case OperandImmediate, OperandAddress:
+		return int64(binary.BigEndian.Uint64(b)) // #nosec: G115
+
+	case OperandRegister, OperandCount:
+		return int64(b[0] & NumRegistersMask)
+
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,62 @@
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetOperandKinds(t *testing.T) {
+	t.Parallel()
+
+	for opcode, length := range instructionLengths {
+		operandsLen := uint64(1)
+
+		for _, kind := range GetOperandKinds(opcode) {
+			operandsLen += kind.Size()
+		}
+
+		if operandsLen != length {
+			t.Fatalf(
+				"expected the operands of opcode %d to take %d bytes, got %d",
+				opcode,
+				length,
+				operandsLen,
+			)
+		}
+	}
+
+	if GetOperandKinds(0xFF) != nil {
+		t.Fatalf("expected an unknown opcode to have no operands")
+	}
+}
+
+func TestDecodeOperands(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeNop),
+		byte(OpcodeHostCall), 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 33, 2,
+		byte(OpcodeJmpImmediate), 0, 0,
+	}
+
+	operands, err := DecodeOperands(program, 1)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	expected := []Operand{
+		{Kind: OperandImmediate, Value: -1},
+		{Kind: OperandRegister, Value: 1},
+		{Kind: OperandCount, Value: 2},
+	}
+
+	if !reflect.DeepEqual(operands, expected) {
+		t.Fatalf("expected operands to be %v, got %v", expected, operands)
+	}
+
+	if _, err = DecodeOperands(program, 12); !errors.Is(err, ErrTruncatedInstruction) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrTruncatedInstruction, err)
+	}
+}
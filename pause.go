@@ -0,0 +1,56 @@
+package vm
+
+// PauseReason defines why the VM paused.
+type PauseReason byte
+
+const (
+	// PauseReasonNone means that the VM did not pause.
+	PauseReasonNone PauseReason = iota
+	// PauseReasonBreakpoint means that the VM reached a breakpoint.
+	PauseReasonBreakpoint
+	// PauseReasonWatchpoint means that an instruction accessed a heap address
+	// that is being watched.
+	PauseReasonWatchpoint
+)
+
+var pauseReasonNames = map[PauseReason]string{
+	PauseReasonNone:       "none",
+	PauseReasonBreakpoint: "breakpoint",
+	PauseReasonWatchpoint: "watchpoint",
+}
+
+// String returns a human-readable name of the reason.
+func (r PauseReason) String() string {
+	name, hasName := pauseReasonNames[r]
+
+	if !hasName {
+		return "unknown"
+	}
+
+	return name
+}
+
+// Pause describes why and where the VM paused.
+type Pause struct {
+	// Why the VM paused.
+	Reason PauseReason
+	// For a breakpoint, the address of the instruction that has not been
+	// executed yet. For a watchpoint, the address of the instruction that
+	// accessed the heap, which has already been executed.
+	PC register
+	// The heap address that has been accessed.
+	// This is only set for a watchpoint.
+	Address register
+	// Whether the heap address has been read or written.
+	// This is only set for a watchpoint.
+	Access WatchKind
+}
+
+func noPause() Pause {
+	return Pause{
+		Reason:  PauseReasonNone,
+		PC:      0,
+		Address: 0,
+		Access:  0,
+	}
+}
@@ -0,0 +1,68 @@
+package profiler
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// RootFunctionName is the name of the function at the root of the call tree,
+// which contains the instructions that have been executed outside of any call.
+const RootFunctionName = "main"
+
+// functionName returns the name of the function that is executing in node.
+func (p *Profiler) functionName(node *callNode) string {
+	if node.parent == nil {
+		return RootFunctionName
+	}
+
+	symbol, hasSymbol := p.symbols.Lookup(node.target)
+
+	if hasSymbol {
+		return symbol.Name
+	}
+
+	return fmt.Sprintf("sub_%04x", node.target)
+}
+
+// stack returns the nodes from the root of the call tree up to and including
+// node.
+func stack(node *callNode) []*callNode {
+	nodes := []*callNode{}
+
+	for n := node; n != nil; n = n.parent {
+		nodes = append(nodes, n)
+	}
+
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+
+	return nodes
+}
+
+func compareCallKeys(a, b callKey) int {
+	return cmp.Or(
+		cmp.Compare(a.callSite, b.callSite),
+		cmp.Compare(a.target, b.target),
+	)
+}
+
+// walk calls fn for every node in the call tree, parents before children.
+// Children are visited in order of their call site and target.
+func (p *Profiler) walk(fn func(node *callNode)) {
+	var visit func(node *callNode)
+
+	visit = func(node *callNode) {
+		fn(node)
+
+		keys := slices.SortedFunc(maps.Keys(node.children), compareCallKeys)
+
+		for _, key := range keys {
+			visit(node.children[key])
+		}
+	}
+
+	visit(p.root)
+}
@@ -0,0 +1,132 @@
+// Package profiler provides an execution profiler for vee-em bytecode.
+package profiler
+
+import vm "github.com/Dobefu/vee-em"
+
+// callNode is a node in the call tree that the profiler builds.
+type callNode struct {
+	// The parent node, or nil for the root of the call tree.
+	parent *callNode
+	// The address of the call instruction that created this node.
+	callSite uint64
+	// The address of the function that has been called.
+	target uint64
+	// The child nodes, keyed by call site and target.
+	children map[callKey]*callNode
+	// The number of executed instructions per program counter.
+	counts map[uint64]uint64
+}
+
+type callKey struct {
+	callSite uint64
+	target   uint64
+}
+
+// Profiler counts the number of executed instructions per program counter,
+// per opcode and per call stack.
+//
+// Call stacks are derived from OpcodeCallImmediate, OpcodeCallRegister
+// and OpcodeReturn.
+type Profiler struct {
+	symbols      *vm.SymbolTable
+	root         *callNode
+	current      *callNode
+	pcCounts     map[uint64]uint64
+	opcodeCounts map[vm.Opcode]uint64
+	total        uint64
+}
+
+// New creates a new profiler.
+// When symbols is not nil, it is used to name the functions in the profile.
+func New(symbols *vm.SymbolTable) *Profiler {
+	root := newCallNode(nil, 0, 0)
+
+	return &Profiler{
+		symbols:      symbols,
+		root:         root,
+		current:      root,
+		pcCounts:     map[uint64]uint64{},
+		opcodeCounts: map[vm.Opcode]uint64{},
+		total:        0,
+	}
+}
+
+func newCallNode(parent *callNode, callSite uint64, target uint64) *callNode {
+	return &callNode{
+		parent:   parent,
+		callSite: callSite,
+		target:   target,
+		children: map[callKey]*callNode{},
+		counts:   map[uint64]uint64{},
+	}
+}
+
+// TraceHook returns the trace hook that feeds the profiler.
+// Pass it to vm.WithTraceHook to profile a VM.
+func (p *Profiler) TraceHook() vm.TraceHook {
+	return vm.TraceHook{
+		Before: nil,
+		After:  p.after,
+	}
+}
+
+func (p *Profiler) after(event *vm.TraceEvent) error {
+	p.total++
+	p.pcCounts[event.PC]++
+	p.opcodeCounts[event.Opcode]++
+	p.current.counts[event.PC]++
+
+	if event.Err != nil {
+		return nil
+	}
+
+	switch event.Opcode {
+	case vm.OpcodeCallImmediate, vm.OpcodeCallRegister:
+		key := callKey{callSite: event.PC, target: event.NextPC}
+		child, hasChild := p.current.children[key]
+
+		if !hasChild {
+			child = newCallNode(p.current, event.PC, event.NextPC)
+			p.current.children[key] = child
+		}
+
+		p.current = child
+
+	case vm.OpcodeReturn:
+		if p.current.parent != nil {
+			p.current = p.current.parent
+		}
+
+	default:
+		// Other instructions do not change the call stack.
+	}
+
+	return nil
+}
+
+// Total returns the total number of executed instructions.
+func (p *Profiler) Total() uint64 {
+	return p.total
+}
+
+// PCCounts returns the number of executed instructions per program counter.
+func (p *Profiler) PCCounts() map[uint64]uint64 {
+	counts := make(map[uint64]uint64, len(p.pcCounts))
+
+	for pc, count := range p.pcCounts {
+		counts[pc] = count
+	}
+
+	return counts
+}
+
+// OpcodeCounts returns the number of executed instructions per opcode.
+func (p *Profiler) OpcodeCounts() map[vm.Opcode]uint64 {
+	counts := make(map[vm.Opcode]uint64, len(p.opcodeCounts))
+
+	for opcode, count := range p.opcodeCounts {
+		counts[opcode] = count
+	}
+
+	return counts
+}
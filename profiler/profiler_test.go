@@ -0,0 +1,147 @@
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	vm "github.com/Dobefu/vee-em"
+)
+
+var program = []byte{
+	byte(vm.OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 10,
+	byte(vm.OpcodeHalt),
+	byte(vm.OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 29,
+	byte(vm.OpcodeCallRegister), 0,
+	byte(vm.OpcodeReturn),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeNop),
+	byte(vm.OpcodeReturn),
+}
+
+func runProfiler(t *testing.T, symbols *vm.SymbolTable) *Profiler {
+	t.Helper()
+
+	p := New(symbols)
+
+	if _, err := vm.New(program, vm.WithTraceHook(p.TraceHook())).Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	return p
+}
+
+func TestProfiler(t *testing.T) {
+	t.Parallel()
+
+	p := runProfiler(t, nil)
+
+	if p.Total() != 6 {
+		t.Fatalf("expected 6 instructions, got %d", p.Total())
+	}
+
+	if counts := p.PCCounts(); len(counts) != 6 || counts[22] != 1 {
+		t.Fatalf("expected 6 program counters to be counted once, got %v", counts)
+	}
+
+	if counts := p.OpcodeCounts(); counts[vm.OpcodeReturn] != 2 || counts[vm.OpcodeHalt] != 1 {
+		t.Fatalf("expected 2 returns and 1 halt, got %v", counts)
+	}
+}
+
+func TestProfilerOutOfFuel(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil)
+	v := vm.New(program, vm.WithFuel(3, nil), vm.WithTraceHook(p.TraceHook()))
+
+	if _, err := v.Run(); !errors.Is(err, vm.ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", vm.ErrOutOfFuel, err)
+	}
+
+	v.AddFuel(10)
+
+	if _, err := v.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if counts := p.PCCounts(); p.Total() != 6 || counts[20] != 1 {
+		t.Fatalf("expected the retried instruction to be counted once, got %d in total, %v", p.Total(), counts)
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		symbols  *vm.SymbolTable
+		expected string
+	}{
+		{
+			name:     "without symbols",
+			symbols:  nil,
+			expected: "main 2\nmain;sub_000a 3\nmain;sub_000a;sub_001d 1\n",
+		},
+		{
+			name: "with symbols",
+			symbols: vm.NewSymbolTable([]vm.Symbol{
+				{Name: "f", Address: 10},
+				{Name: "g", Address: 29},
+			}),
+			expected: "main 2\nmain;f 3\nmain;f;g 1\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			if err := runProfiler(t, test.symbols).WriteFolded(&buf); err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			if buf.String() != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	t.Parallel()
+
+	symbols := vm.NewSymbolTable([]vm.Symbol{{Name: "f", Address: 10}})
+
+	var buf bytes.Buffer
+
+	if err := runProfiler(t, symbols).WritePprof(&buf); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	gz, err := gzip.NewReader(&buf)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	data, err := io.ReadAll(gz)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	for _, s := range []string{"instructions", "count", "main", "f", "bytecode"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Fatalf("expected the profile to contain %q", s)
+		}
+	}
+}
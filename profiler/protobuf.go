@@ -0,0 +1,75 @@
+package profiler
+
+import (
+	"encoding/binary"
+)
+
+// protoWireVarint and protoWireBytes are the protobuf wire types
+// that are used in profile.proto.
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+// protoBuffer is a minimal protocol buffer encoder,
+// which supports just enough to encode a profile.proto message.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) tag(field int, wireType int) {
+	// #nosec: G115
+	b.data = binary.AppendUvarint(b.data, uint64(field<<3|wireType))
+}
+
+func (b *protoBuffer) uint64(field int, value uint64) {
+	if value == 0 {
+		return
+	}
+
+	b.tag(field, protoWireVarint)
+	b.data = binary.AppendUvarint(b.data, value)
+}
+
+func (b *protoBuffer) int64(field int, value int64) {
+	// #nosec: G115
+	b.uint64(field, uint64(value))
+}
+
+func (b *protoBuffer) packedUint64s(field int, values []uint64) {
+	packed := protoBuffer{data: nil}
+
+	for _, value := range values {
+		packed.data = binary.AppendUvarint(packed.data, value)
+	}
+
+	b.bytes(field, packed.data)
+}
+
+func (b *protoBuffer) packedInt64s(field int, values []int64) {
+	packed := protoBuffer{data: nil}
+
+	for _, value := range values {
+		// #nosec: G115
+		packed.data = binary.AppendUvarint(packed.data, uint64(value))
+	}
+
+	b.bytes(field, packed.data)
+}
+
+func (b *protoBuffer) bytes(field int, value []byte) {
+	b.tag(field, protoWireBytes)
+	b.data = binary.AppendUvarint(b.data, uint64(len(value)))
+	b.data = append(b.data, value...)
+}
+
+func (b *protoBuffer) string(field int, value string) {
+	b.bytes(field, []byte(value))
+}
+
+func (b *protoBuffer) message(field int, fn func(m *protoBuffer)) {
+	m := protoBuffer{data: nil}
+	fn(&m)
+
+	b.bytes(field, m.data)
+}
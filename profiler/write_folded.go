@@ -0,0 +1,52 @@
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WriteFolded writes the profile in the folded stacks format,
+// which is used by flame graph tools.
+// Every line contains the function names from the root of the call tree,
+// separated by semicolons, followed by the number of executed instructions.
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	totals := map[string]uint64{}
+
+	p.walk(func(node *callNode) {
+		var count uint64
+
+		for _, c := range node.counts {
+			count += c
+		}
+
+		if count == 0 {
+			return
+		}
+
+		names := []string{}
+
+		for _, n := range stack(node) {
+			names = append(names, p.functionName(n))
+		}
+
+		totals[strings.Join(names, ";")] += count
+	})
+
+	lines := make([]string, 0, len(totals))
+
+	for folded, count := range totals {
+		lines = append(lines, fmt.Sprintf("%s %d\n", folded, count))
+	}
+
+	slices.Sort(lines)
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("could not write folded stacks: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Field numbers of the profile.proto messages.
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+	profilePeriodType  = 11
+	profilePeriod      = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID      = 1
+	locationAddress = 3
+	locationLine    = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+// pprofFilename is the filename that is reported for every function.
+// Line numbers in the profile are addresses in the program.
+const pprofFilename = "bytecode"
+
+type pprofLocationKey struct {
+	address  uint64
+	function string
+}
+
+// pprofEncoder collects the string table, functions and locations
+// of a profile, while the samples are being added.
+type pprofEncoder struct {
+	strings     []string
+	stringIDs   map[string]int64
+	functionIDs map[string]uint64
+	locations   []pprofLocationKey
+	locationIDs map[pprofLocationKey]uint64
+	samples     protoBuffer
+}
+
+func newPprofEncoder() *pprofEncoder {
+	return &pprofEncoder{
+		strings:     []string{""},
+		stringIDs:   map[string]int64{"": 0},
+		functionIDs: map[string]uint64{},
+		locations:   nil,
+		locationIDs: map[pprofLocationKey]uint64{},
+		samples:     protoBuffer{data: nil},
+	}
+}
+
+func (e *pprofEncoder) stringID(s string) int64 {
+	id, hasID := e.stringIDs[s]
+
+	if !hasID {
+		id = int64(len(e.strings))
+		e.strings = append(e.strings, s)
+		e.stringIDs[s] = id
+	}
+
+	return id
+}
+
+func (e *pprofEncoder) locationID(address uint64, function string) uint64 {
+	key := pprofLocationKey{address: address, function: function}
+	id, hasID := e.locationIDs[key]
+
+	if !hasID {
+		if _, hasFunction := e.functionIDs[function]; !hasFunction {
+			e.functionIDs[function] = uint64(len(e.functionIDs) + 1)
+		}
+
+		e.locations = append(e.locations, key)
+		id = uint64(len(e.locations))
+		e.locationIDs[key] = id
+	}
+
+	return id
+}
+
+// WritePprof writes the profile as a gzip-compressed profile.proto message,
+// which can be opened with "go tool pprof".
+// Every sample is the number of instructions that have been executed
+// at a program counter, with the call stack that it was executed in.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	e := newPprofEncoder()
+
+	p.walk(func(node *callNode) {
+		nodes := stack(node)
+
+		for _, pc := range slices.Sorted(maps.Keys(node.counts)) {
+			locationIDs := []uint64{e.locationID(pc, p.functionName(node))}
+
+			for i := len(nodes) - 1; i > 0; i-- {
+				locationIDs = append(
+					locationIDs,
+					e.locationID(nodes[i].callSite, p.functionName(nodes[i-1])),
+				)
+			}
+
+			e.samples.message(profileSample, func(m *protoBuffer) {
+				m.packedUint64s(sampleLocationID, locationIDs)
+				// #nosec: G115
+				m.packedInt64s(sampleValue, []int64{int64(node.counts[pc])})
+			})
+		}
+	})
+
+	gz := gzip.NewWriter(w)
+
+	if _, err := gz.Write(e.encode()); err != nil {
+		return fmt.Errorf("could not write profile: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("could not write profile: %w", err)
+	}
+
+	return nil
+}
+
+func (e *pprofEncoder) encode() []byte {
+	b := protoBuffer{data: nil}
+
+	valueType := func(m *protoBuffer) {
+		m.int64(valueTypeType, e.stringID("instructions"))
+		m.int64(valueTypeUnit, e.stringID("count"))
+	}
+
+	b.message(profileSampleType, valueType)
+	b.data = append(b.data, e.samples.data...)
+
+	for i, location := range e.locations {
+		b.message(profileLocation, func(m *protoBuffer) {
+			m.uint64(locationID, uint64(i+1))
+			m.uint64(locationAddress, location.address)
+			m.message(locationLine, func(line *protoBuffer) {
+				line.uint64(lineFunctionID, e.functionIDs[location.function])
+				// #nosec: G115
+				line.int64(lineLine, int64(location.address))
+			})
+		})
+	}
+
+	functions := slices.SortedFunc(
+		maps.Keys(e.functionIDs),
+		func(a, b string) int {
+			return int(e.functionIDs[a]) - int(e.functionIDs[b])
+		},
+	)
+
+	for _, function := range functions {
+		b.message(profileFunction, func(m *protoBuffer) {
+			m.uint64(functionID, e.functionIDs[function])
+			m.int64(functionName, e.stringID(function))
+			m.int64(functionSystemName, e.stringID(function))
+			m.int64(functionFilename, e.stringID(pprofFilename))
+		})
+	}
+
+	b.message(profilePeriodType, valueType)
+	b.int64(profilePeriod, 1)
+
+	for _, s := range e.strings {
+		b.string(profileStringTable, s)
+	}
+
+	return b.data
+}
@@ -0,0 +1,39 @@
+package vm
+
+// Reset clears the state of the VM in place, so that it can run again without
+// allocating a new VM.
+// The program, options and handlers are kept,
+// and the fuel is refilled to the limit that was set with WithFuel.
+// Breakpoints and watchpoints are removed.
+//
+// When keepHeap is true, the heap is left untouched.
+// This is useful to keep data around in between runs.
+// Otherwise, the heap is cleared, and the heap image is stored in it again.
+func (v *VM) Reset(keepHeap bool) {
+	v.pc = register(len(v.magicHeader))
+	v.registers = [NumRegisters]int64{}
+	v.flags = flags{
+		isZero:     false,
+		isNegative: false,
+	}
+
+	clear(v.stack)
+	v.sp = 0
+	v.callStack = v.callStack[:0]
+
+	if !keepHeap {
+		v.clearHeap()
+		v.loadHeapImage()
+	}
+
+	clear(v.breakpoints)
+	clear(v.watchpoints)
+
+	v.halted = false
+	v.skipBreakpoint = false
+	v.clearUndo()
+	v.pause = noPause()
+	v.exitStatus = 0
+	v.instructionCount = 0
+	v.fuel = v.fuelLimit
+}
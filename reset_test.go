@@ -0,0 +1,138 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		0x00,
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 42,
+		byte(OpcodePush), 0,
+		byte(OpcodeStoreMemory), 0, 0,
+		byte(OpcodeSub), 1, 1, 0,
+	}
+
+	tests := []struct {
+		name         string
+		keepHeap     bool
+		expectedHeap int64
+	}{
+		{
+			name:         "reset",
+			keepHeap:     false,
+			expectedHeap: 0,
+		},
+		{
+			name:         "reset keep heap",
+			keepHeap:     true,
+			expectedHeap: 42,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			vm := New(program, WithMagicHeader([]byte{0x00}), WithFuel(10, nil))
+
+			if _, err := vm.Run(); err != nil {
+				t.Fatalf("expected no error, got %s", err.Error())
+			}
+
+			vm.Reset(test.keepHeap)
+
+			if vm.pc != 1 || vm.sp != 0 || vm.registers != [NumRegisters]int64{} {
+				t.Fatalf("expected pc 1, sp 0 and empty registers, got pc %d, sp %d, registers %v", vm.pc, vm.sp, vm.registers)
+			}
+
+			if vm.flags.isNegative || vm.fuel != 10 {
+				t.Fatalf("expected flags to be cleared and fuel to be refilled, got %v and %d", vm.flags, vm.fuel)
+			}
+
+			if vm.loadHeap(42) != test.expectedHeap {
+				t.Fatalf("expected heap value to be %d, got %d", test.expectedHeap, vm.loadHeap(42))
+			}
+		})
+	}
+}
+
+func TestLoadProgram(t *testing.T) {
+	t.Parallel()
+
+	vm := New(
+		[]byte{0x00, byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		WithMagicHeader([]byte{0x00}),
+	)
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	err := vm.LoadProgram([]byte{0xFF, byte(OpcodeNop)})
+
+	if !errors.Is(err, ErrInvalidMagicHeader) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrInvalidMagicHeader, err)
+	}
+
+	if err = vm.LoadProgram([]byte{0x00, byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 2}); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.pc != 1 {
+		t.Fatalf("expected pc to be 1, got %d", vm.pc)
+	}
+
+	if _, err = vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.registers[0] != 1 || vm.registers[1] != 2 {
+		t.Fatalf("expected registers to be [1 2 ...], got %v", vm.registers)
+	}
+}
+
+func TestResetDebugState(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{0x00, byte(OpcodeNop), byte(OpcodeNop)}
+	vm := New(program, WithMagicHeader([]byte{0x00}))
+
+	setDebugState := func() {
+		if err := vm.SetBreakpoint(2); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if err := vm.SetWatchpoint(3, WatchRead); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if result, _ := vm.Run(); result.Reason != StopReasonPaused {
+			t.Fatalf("expected to pause at the breakpoint, got %+v", result)
+		}
+	}
+
+	setDebugState()
+	vm.Reset(false)
+
+	if len(vm.Breakpoints()) != 0 || len(vm.Watchpoints()) != 0 || vm.skipBreakpoint {
+		t.Fatalf("expected Reset to remove breakpoints and watchpoints")
+	}
+
+	setDebugState()
+
+	if err := vm.LoadProgram(program); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(vm.Breakpoints()) != 0 || vm.skipBreakpoint {
+		t.Fatalf("expected LoadProgram to remove breakpoints, got %v", vm.Breakpoints())
+	}
+
+	if result, _ := vm.Run(); result.Reason != StopReasonEndOfProgram {
+		t.Fatalf("expected to run to the end of the program, got %+v", result)
+	}
+}
@@ -0,0 +1,193 @@
+package vm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// heapRun is a run of consecutive values in the heap.
+type heapRun struct {
+	addr   uint64
+	values []int64
+}
+
+// Restore replaces the machine state with a snapshot that was created with
+// Snapshot, so that execution can be resumed where the snapshot was taken.
+//
+// The VM must run the same program as the VM the snapshot was taken of,
+// and must have the same heap, stack and call stack sizes.
+// When the snapshot cannot be restored, the VM is left untouched.
+func (v *VM) Restore(data []byte) error {
+	r := newBinaryReader(data, "snapshot", ErrInvalidSnapshot)
+
+	err := v.readSnapshotHeader(r)
+
+	if err != nil {
+		return err
+	}
+
+	pc := r.uint64()
+	snapshotFlags := r.byte()
+	registers := r.registers()
+	stack := r.stack(r.size(uint64(len(v.stack)), "stack"))
+	callStack := r.callStack(r.size(v.callStackSize, "call stack"))
+	heapRuns := r.heapRuns(r.size(v.heapSize, "heap"))
+	fuel := r.uint64()
+	exitStatus := int64(r.uint64()) // #nosec: G115
+	instructionCount := r.uint64()
+
+	if r.err == nil && len(r.data) != 0 {
+		r.fail("unexpected trailing data")
+	}
+
+	if r.err == nil && pc > v.programLen {
+		r.fail("program counter out of bounds")
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	v.pc = pc
+	v.flags = flags{
+		isZero:     snapshotFlags&snapshotFlagIsZero != 0,
+		isNegative: snapshotFlags&snapshotFlagIsNegative != 0,
+	}
+	v.halted = snapshotFlags&snapshotFlagHalted != 0
+	v.skipBreakpoint = false
+	v.clearUndo()
+	v.exitStatus = exitStatus
+	v.instructionCount = instructionCount
+	v.registers = registers
+
+	clear(v.stack)
+	copy(v.stack, stack)
+	v.sp = register(len(stack))
+	v.callStack = append(v.callStack[:0], callStack...)
+
+	v.clearHeap()
+
+	for _, run := range heapRuns {
+		for i, val := range run.values {
+			v.storeHeap(run.addr+uint64(i), val)
+		}
+	}
+
+	if v.fuelEnabled {
+		v.fuel = fuel
+	}
+
+	return nil
+}
+
+func (v *VM) readSnapshotHeader(r *binaryReader) error {
+	if !bytes.Equal(r.next(uint64(len(snapshotMagic))), snapshotMagic) {
+		return fmt.Errorf("%w: missing magic number", ErrInvalidSnapshot)
+	}
+
+	version := r.uint16()
+
+	if r.err == nil && version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+
+	programHash := sha256.Sum256(v.program)
+	snapshotProgramHash := r.next(sha256.Size)
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if !bytes.Equal(snapshotProgramHash, programHash[:]) {
+		return ErrProgramMismatch
+	}
+
+	return nil
+}
+
+// size reads the size of a memory region, which has to match the VM.
+func (r *binaryReader) size(expected uint64, name string) uint64 {
+	size := r.uint64()
+
+	if r.err == nil && size != expected {
+		r.fail(fmt.Sprintf("%s size %d does not match %d", name, size, expected))
+	}
+
+	return size
+}
+
+func (r *binaryReader) stack(stackSize uint64) []int64 {
+	sp := r.uint64()
+
+	if r.err == nil && sp > stackSize {
+		r.fail("stack pointer out of bounds")
+	}
+
+	return r.values(sp)
+}
+
+func (r *binaryReader) callStack(callStackSize uint64) []CallFrame {
+	depth := r.uint64()
+
+	// Every call frame takes 24 bytes, which puts a limit on the allocation.
+	if r.err == nil && (depth > callStackSize || depth > uint64(len(r.data))/24) {
+		r.fail("call stack depth out of bounds")
+	}
+
+	if r.err != nil {
+		return nil
+	}
+
+	callStack := make([]CallFrame, depth)
+
+	for i := range callStack {
+		callStack[i] = CallFrame{
+			CallSite:      r.uint64(),
+			Target:        r.uint64(),
+			ReturnAddress: r.uint64(),
+		}
+	}
+
+	return callStack
+}
+
+func (r *binaryReader) registers() [NumRegisters]int64 {
+	registers := [NumRegisters]int64{}
+
+	if r.uint64() != NumRegisters {
+		r.fail("register count mismatch")
+
+		return registers
+	}
+
+	copy(registers[:], r.values(NumRegisters))
+
+	return registers
+}
+
+func (r *binaryReader) heapRuns(heapSize uint64) []heapRun {
+	numRuns := r.uint64()
+
+	// Every run takes at least 16 bytes, which puts a limit on the allocation.
+	if r.err != nil || numRuns > uint64(len(r.data))/16 {
+		r.failEnd()
+
+		return nil
+	}
+
+	runs := make([]heapRun, 0, numRuns)
+
+	for range numRuns {
+		addr := r.uint64()
+		runLen := r.uint64()
+
+		if r.err == nil && (addr > heapSize || runLen > heapSize-addr) {
+			r.fail("heap address out of bounds")
+		}
+
+		runs = append(runs, heapRun{addr: addr, values: r.values(runLen)})
+	}
+
+	return runs
+}
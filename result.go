@@ -0,0 +1,104 @@
+package vm
+
+import (
+	"errors"
+)
+
+// StopReason defines why the VM stopped running.
+type StopReason byte
+
+const (
+	// StopReasonHalted means that the program stopped itself
+	// with OpcodeHalt or OpcodeHaltRegister.
+	StopReasonHalted StopReason = iota
+	// StopReasonEndOfProgram means that the program ran off its end.
+	StopReasonEndOfProgram
+	// StopReasonOutOfFuel means that there was not enough fuel left to execute
+	// the next instruction.
+	StopReasonOutOfFuel
+	// StopReasonCanceled means that the context was canceled,
+	// or that its deadline has passed.
+	StopReasonCanceled
+	// StopReasonFaulted means that an error occurred.
+	StopReasonFaulted
+	// StopReasonPaused means that a breakpoint or watchpoint has been hit.
+	// Running the VM again resumes execution where it stopped.
+	StopReasonPaused
+)
+
+var stopReasonNames = map[StopReason]string{
+	StopReasonHalted:       "halted",
+	StopReasonEndOfProgram: "end of program",
+	StopReasonOutOfFuel:    "out of fuel",
+	StopReasonCanceled:     "canceled",
+	StopReasonFaulted:      "faulted",
+	StopReasonPaused:       "paused",
+}
+
+// String returns a human-readable name of the reason.
+func (r StopReason) String() string {
+	name, hasName := stopReasonNames[r]
+
+	if !hasName {
+		return "unknown"
+	}
+
+	return name
+}
+
+// Result describes the outcome of running the VM.
+type Result struct {
+	// Why the VM stopped running.
+	Reason StopReason
+	// The exit status of the program.
+	// This is only set when the program stopped with OpcodeHaltRegister.
+	ExitStatus int64
+	// The number of instructions that have been executed during the run.
+	Instructions uint64
+	// Why and where the VM paused.
+	// This is only set when Reason is StopReasonPaused.
+	Pause Pause
+}
+
+// ExitStatus returns the exit status that the program stopped with.
+func (v *VM) ExitStatus() int64 {
+	return v.exitStatus
+}
+
+// InstructionCount returns the total number of instructions that have been
+// executed since the VM was created or reset.
+func (v *VM) InstructionCount() uint64 {
+	return v.instructionCount
+}
+
+func (v *VM) newResult(startCount uint64, err error) Result {
+	result := Result{
+		Reason:       StopReasonEndOfProgram,
+		ExitStatus:   v.exitStatus,
+		Instructions: v.instructionCount - startCount,
+		Pause:        noPause(),
+	}
+
+	switch {
+	case err == nil && v.pause.Reason != PauseReasonNone:
+		result.Reason = StopReasonPaused
+		result.Pause = v.pause
+
+	case err == nil && v.halted:
+		result.Reason = StopReasonHalted
+
+	case err == nil:
+		result.Reason = StopReasonEndOfProgram
+
+	case errors.Is(err, ErrOutOfFuel):
+		result.Reason = StopReasonOutOfFuel
+
+	case errors.Is(err, ErrCanceled), errors.Is(err, ErrDeadlineExceeded):
+		result.Reason = StopReasonCanceled
+
+	default:
+		result.Reason = StopReasonFaulted
+	}
+
+	return result
+}
@@ -0,0 +1,123 @@
+package vm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		program  []byte
+		options  []Option
+		ctx      context.Context
+		expected Result
+	}{
+		{
+			name: "halted",
+			program: []byte{
+				byte(OpcodeNop),
+				byte(OpcodeHalt),
+				byte(OpcodeNop),
+			},
+			options: nil,
+			ctx:     context.Background(),
+			expected: Result{
+				Reason:       StopReasonHalted,
+				ExitStatus:   0,
+				Instructions: 2,
+				Pause:        noPause(),
+			},
+		},
+		{
+			name: "halted with exit status",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 3, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE,
+				byte(OpcodeHaltRegister), 3,
+			},
+			options: nil,
+			ctx:     context.Background(),
+			expected: Result{
+				Reason:       StopReasonHalted,
+				ExitStatus:   -2,
+				Instructions: 2,
+				Pause:        noPause(),
+			},
+		},
+		{
+			name: "end of program",
+			program: []byte{
+				byte(OpcodeNop),
+			},
+			options: nil,
+			ctx:     context.Background(),
+			expected: Result{
+				Reason:       StopReasonEndOfProgram,
+				ExitStatus:   0,
+				Instructions: 1,
+				Pause:        noPause(),
+			},
+		},
+		{
+			name: "out of fuel",
+			program: []byte{
+				byte(OpcodeNop),
+				byte(OpcodeNop),
+			},
+			options: []Option{WithFuel(1, nil)},
+			ctx:     context.Background(),
+			expected: Result{
+				Reason:       StopReasonOutOfFuel,
+				ExitStatus:   0,
+				Instructions: 1,
+				Pause:        noPause(),
+			},
+		},
+		{
+			name: "canceled",
+			program: []byte{
+				byte(OpcodeNop),
+			},
+			options: nil,
+			ctx:     canceledCtx,
+			expected: Result{
+				Reason:       StopReasonCanceled,
+				ExitStatus:   0,
+				Instructions: 0,
+				Pause:        noPause(),
+			},
+		},
+		{
+			name: "faulted",
+			program: []byte{
+				byte(OpcodeNop),
+				byte(OpcodePop), 0,
+			},
+			options: nil,
+			ctx:     context.Background(),
+			expected: Result{
+				Reason:       StopReasonFaulted,
+				ExitStatus:   0,
+				Instructions: 1,
+				Pause:        noPause(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, _ := New(test.program, test.options...).RunContext(test.ctx)
+
+			if result != test.expected {
+				t.Fatalf("expected result to be %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
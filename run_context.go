@@ -0,0 +1,93 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// cancellationCheckInterval is the number of instructions to execute between
+// checks of the context.
+const cancellationCheckInterval = 1024
+
+// RunContext runs the VM until the program stops, or until ctx is done.
+//
+// The context is only checked in between instructions,
+// so the VM is left in a consistent state when execution is cut short.
+// Calling RunContext or Run again resumes execution where it stopped.
+//
+// When a breakpoint or watchpoint is hit, the VM pauses,
+// and the result has StopReasonPaused with a nil error.
+func (v *VM) RunContext(ctx context.Context) (Result, error) {
+	startCount := v.instructionCount
+	v.pause = noPause()
+	err := v.runContext(ctx)
+
+	return v.newResult(startCount, err), err
+}
+
+func (v *VM) runContext(ctx context.Context) error {
+	err := v.validateMagicHeader()
+
+	if err == nil {
+		err = v.heapImageErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	done := ctx.Done()
+
+	for i := uint64(0); v.pc < v.programLen; i++ {
+		if len(v.breakpoints) != 0 && v.hitBreakpoint() {
+			return nil
+		}
+
+		if done != nil && i%cancellationCheckInterval == 0 {
+			err = contextError(ctx)
+
+			if err != nil {
+				// The breakpoint at the instruction has already been checked,
+				// so resuming should not pause at it again.
+				v.skipBreakpoint = true
+
+				return err
+			}
+		}
+
+		_, err = v.step()
+
+		if errors.Is(err, ErrOutOfFuel) {
+			// The instruction has been rolled back, and its breakpoint has
+			// already been checked, so resuming should not pause at it again.
+			v.skipBreakpoint = true
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if v.watchpointHit.Reason != PauseReasonNone {
+			v.pause = v.watchpointHit
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrDeadlineExceeded, err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrCanceled, err)
+}
@@ -0,0 +1,67 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunContext(t *testing.T) {
+	t.Parallel()
+
+	// An endless loop that keeps incrementing register 0.
+	program := []byte{
+		byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodeAdd), 0, 0, 1,
+		byte(OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0, 10,
+	}
+
+	tests := []struct {
+		name     string
+		newCtx   func() (context.Context, context.CancelFunc)
+		expected error
+	}{
+		{
+			name: "canceled",
+			newCtx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx, cancel
+			},
+			expected: ErrCanceled,
+		},
+		{
+			name: "deadline exceeded",
+			newCtx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			expected: ErrDeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := test.newCtx()
+			defer cancel()
+
+			vm := New(program)
+			_, err := vm.RunContext(ctx)
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+
+			if vm.PC() >= register(len(program)) {
+				t.Fatalf("expected the VM to be stopped mid-program, got pc %d", vm.PC())
+			}
+
+			if vm.PC() != 0 && vm.PC() != 10 && vm.PC() != 14 {
+				t.Fatalf("expected pc to be at an instruction boundary, got %d", vm.PC())
+			}
+		})
+	}
+}
@@ -911,11 +911,11 @@ func TestRun(t *testing.T) {
 			name: "return",
 			program: []byte{
 				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 42,
-				byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 33,
-				byte(OpcodePush), 1,
+				byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 30,
+				byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 200,
+				byte(OpcodeHalt),
 				byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 100,
 				byte(OpcodeReturn),
-				byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 200,
 			},
 			hostCallHandler:   nil,
 			expectedRegisters: [NumRegisters]int64{42, 200},
@@ -944,6 +944,20 @@ func TestRun(t *testing.T) {
 				isNegative: false,
 			},
 		},
+		{
+			name: "halt register",
+			program: []byte{
+				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 42,
+				byte(OpcodeHaltRegister), 0,
+				byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 100, // This should not get executed.
+			},
+			hostCallHandler:   nil,
+			expectedRegisters: [NumRegisters]int64{42},
+			expectedFlags: flags{
+				isZero:     false,
+				isNegative: false,
+			},
+		},
 		{
 			name: "halt",
 			program: []byte{
@@ -965,7 +979,7 @@ func TestRun(t *testing.T) {
 			t.Parallel()
 
 			vm := New(test.program, WithHostCallHandler(test.hostCallHandler))
-			err := vm.Run()
+			_, err := vm.Run()
 
 			if err != nil {
 				t.Fatalf("expected no error, got %s", err.Error())
@@ -993,6 +1007,8 @@ func TestRun(t *testing.T) {
 func TestRunErr(t *testing.T) {
 	t.Parallel()
 
+	errHostCallHandler := errors.New("host call handler error")
+
 	tests := []struct {
 		name            string
 		program         []byte
@@ -1003,7 +1019,7 @@ func TestRunErr(t *testing.T) {
 			name:            "missing magic header",
 			program:         []byte{},
 			hostCallHandler: nil,
-			expected:        errors.New("invalid magic header"),
+			expected:        ErrInvalidMagicHeader,
 		},
 		{
 			name: "invalid magic header",
@@ -1012,7 +1028,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeNop),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("invalid magic header"),
+			expected:        ErrInvalidMagicHeader,
 		},
 		{
 			name: "unexpected end of program",
@@ -1021,7 +1037,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeLoadImmediate),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode push too few arguments",
@@ -1030,7 +1046,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodePush),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode pop too few arguments",
@@ -1039,7 +1055,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodePop),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode load register too few arguments",
@@ -1048,7 +1064,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeLoadRegister),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode load memory too few arguments",
@@ -1057,7 +1073,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeLoadMemory),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode store memory too few arguments",
@@ -1066,7 +1082,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeStoreMemory),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode add too few arguments",
@@ -1075,7 +1091,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeAdd),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode sub too few arguments",
@@ -1084,7 +1100,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeSub),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode mul too few arguments",
@@ -1093,7 +1109,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeMul),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode div too few arguments",
@@ -1102,7 +1118,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeDiv),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode mod too few arguments",
@@ -1111,7 +1127,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeMod),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode AND too few arguments",
@@ -1120,7 +1136,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeAND),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode OR too few arguments",
@@ -1129,7 +1145,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeOR),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode XOR too few arguments",
@@ -1138,7 +1154,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeXOR),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode NOT too few arguments",
@@ -1147,7 +1163,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeNOT),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode shift left too few arguments",
@@ -1156,7 +1172,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeShiftLeft),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode shift right too few arguments",
@@ -1165,7 +1181,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeShiftRight),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode shift right arithmetic too few arguments",
@@ -1174,7 +1190,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeShiftRightArithmetic),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode CMP too few arguments",
@@ -1183,7 +1199,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeCMP),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate too few arguments",
@@ -1192,7 +1208,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediate),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if zero too few arguments",
@@ -1201,7 +1217,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfZero),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if not zero too few arguments",
@@ -1210,7 +1226,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfNotZero),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if equal too few arguments",
@@ -1219,7 +1235,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if not equal too few arguments",
@@ -1228,7 +1244,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfNotEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if greater too few arguments",
@@ -1237,7 +1253,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfGreater),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if greater or equal too few arguments",
@@ -1246,7 +1262,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfGreaterOrEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if less too few arguments",
@@ -1255,7 +1271,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfLess),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp immediate if less or equal too few arguments",
@@ -1264,7 +1280,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfLessOrEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register too few arguments",
@@ -1273,7 +1289,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegister),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if zero too few arguments",
@@ -1282,7 +1298,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfZero),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if not zero too few arguments",
@@ -1291,7 +1307,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfNotZero),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if equal too few arguments",
@@ -1300,7 +1316,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if not equal too few arguments",
@@ -1309,7 +1325,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfNotEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if greater too few arguments",
@@ -1318,7 +1334,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfGreater),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if greater or equal too few arguments",
@@ -1327,7 +1343,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfGreaterOrEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if less too few arguments",
@@ -1336,7 +1352,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfLess),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode jmp register if less or equal too few arguments",
@@ -1345,7 +1361,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfLessOrEqual),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode call immediate too few arguments",
@@ -1354,7 +1370,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeCallImmediate),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode call register too few arguments",
@@ -1363,7 +1379,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeCallRegister),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "opcode host call too few arguments",
@@ -1372,7 +1388,16 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeHostCall),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unexpected end of program"),
+			expected:        ErrTruncatedInstruction,
+		},
+		{
+			name: "opcode halt register too few arguments",
+			program: []byte{
+				0x00,
+				byte(OpcodeHaltRegister),
+			},
+			hostCallHandler: nil,
+			expected:        ErrTruncatedInstruction,
 		},
 		{
 			name: "division by zero",
@@ -1383,7 +1408,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeDiv), 2, 0, 1,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("division by zero"),
+			expected:        ErrDivisionByZero,
 		},
 		{
 			name: "modulo by zero",
@@ -1394,7 +1419,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeMod), 2, 0, 1,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("modulo by zero"),
+			expected:        ErrDivisionByZero,
 		},
 		{
 			name: "stack overflow",
@@ -1409,7 +1434,7 @@ func TestRunErr(t *testing.T) {
 				return program
 			}(),
 			hostCallHandler: nil,
-			expected:        errors.New("stack overflow"),
+			expected:        ErrStackOverflow,
 		},
 		{
 			name: "stack underflow",
@@ -1418,7 +1443,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodePop), 0,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("stack underflow"),
+			expected:        ErrStackUnderflow,
 		},
 		{
 			name: "unknown opcode",
@@ -1427,7 +1452,7 @@ func TestRunErr(t *testing.T) {
 				byte(255),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("unknown opcode: 11111111"),
+			expected:        ErrUnknownOpcode,
 		},
 		{
 			name: "jmp immediate target out of bounds",
@@ -1436,7 +1461,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 39, 15,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if zero memory address out of bounds",
@@ -1446,7 +1471,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfZero), 0, 0, 0, 0, 0, 0, 0, 0, 21,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if not zero memory address out of bounds",
@@ -1456,7 +1481,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfNotZero), 0, 0, 0, 0, 0, 0, 0, 0, 21,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if equal memory address out of bounds",
@@ -1466,7 +1491,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfEqual), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if not equal memory address out of bounds",
@@ -1476,7 +1501,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfNotEqual), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if greater memory address out of bounds",
@@ -1486,7 +1511,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfGreater), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if greater or equal memory address out of bounds",
@@ -1496,7 +1521,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfGreaterOrEqual), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if less memory address out of bounds",
@@ -1506,7 +1531,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfLess), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp immediate if less or equal memory address out of bounds",
@@ -1516,7 +1541,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpImmediateIfLessOrEqual), 0, 0, 0, 0, 0, 0, 0, 34,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register memory address out of bounds",
@@ -1526,7 +1551,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegister), 0,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if zero memory address out of bounds",
@@ -1537,7 +1562,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfZero), 0, 1,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if not zero memory address out of bounds",
@@ -1548,7 +1573,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfNotZero), 0, 1,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if equal memory address out of bounds",
@@ -1561,7 +1586,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfEqual), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if not equal memory address out of bounds",
@@ -1574,7 +1599,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfNotEqual), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if greater memory address out of bounds",
@@ -1587,7 +1612,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfGreater), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if greater or equal memory address out of bounds",
@@ -1600,7 +1625,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfGreaterOrEqual), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if less memory address out of bounds",
@@ -1613,7 +1638,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfLess), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "jmp register if less or equal memory address out of bounds",
@@ -1626,7 +1651,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeJmpRegisterIfLessOrEqual), 2,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "load memory address out of bounds",
@@ -1636,7 +1661,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeLoadMemory), 1, 0,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "store memory address out of bounds",
@@ -1647,22 +1672,22 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeStoreMemory), 0, 1,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
-			name: "call immediate stack overflow",
+			name: "call immediate call stack overflow",
 			program: func() []byte {
-				program := make([]byte, 0, StackSize*2)
+				program := make([]byte, 0, CallStackSize*2)
 				program = append(program, 0x00)
 
-				for range StackSize + 1 {
+				for range CallStackSize + 1 {
 					program = append(program, byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 10)
 				}
 
 				return program
 			}(),
 			hostCallHandler: nil,
-			expected:        errors.New("stack overflow"),
+			expected:        ErrCallStackOverflow,
 		},
 		{
 			name: "call immediate memory address out of bounds",
@@ -1671,25 +1696,25 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 50,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
-			name: "call register stack overflow",
+			name: "call register call stack overflow",
 			program: func() []byte {
-				program := make([]byte, 0, StackSize*2)
+				program := make([]byte, 0, CallStackSize*2)
 				program = append(program, 0x00)
 				targetAddr := uint64(12)
 				program = append(program, byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, byte(targetAddr))
 				program = append(program, byte(OpcodeNop))
 
-				for range StackSize + 1 {
+				for range CallStackSize + 1 {
 					program = append(program, byte(OpcodeCallRegister), 0)
 				}
 
 				return program
 			}(),
 			hostCallHandler: nil,
-			expected:        errors.New("stack overflow"),
+			expected:        ErrCallStackOverflow,
 		},
 		{
 			name: "call register memory address out of bounds",
@@ -1699,30 +1724,48 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeCallRegister), 0,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
-			name: "return stack underflow",
+			name: "return call stack underflow",
 			program: []byte{
 				0x00,
 				byte(OpcodeReturn),
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("stack underflow"),
+			expected:        ErrCallStackUnderflow,
+		},
+		{
+			name: "return address forged on the stack",
+			program: []byte{
+				0x00,
+				byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+				byte(OpcodePush), 0,
+				byte(OpcodeReturn),
+			},
+			hostCallHandler: nil,
+			expected:        ErrCallStackUnderflow,
+		},
+		{
+			name: "return address popped off the stack",
+			program: []byte{
+				0x00,
+				byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 10,
+				byte(OpcodePop), 0,
+			},
+			hostCallHandler: nil,
+			expected:        ErrStackUnderflow,
 		},
 		{
 			name: "return memory address out of bounds",
-			program: func() []byte {
-				program := make([]byte, 0, 20)
-				program = append(program, 0x00)
-				program = append(program, byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 50)
-				program = append(program, byte(OpcodePush), 0)
-				program = append(program, byte(OpcodeReturn))
-
-				return program
-			}(),
+			program: []byte{
+				0x00,
+				byte(OpcodeJmpImmediate), 0, 0, 0, 0, 0, 0, 0, 11,
+				byte(OpcodeReturn),
+				byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 10,
+			},
 			hostCallHandler: nil,
-			expected:        errors.New("memory address out of bounds"),
+			expected:        ErrOutOfBounds,
 		},
 		{
 			name: "host call handler error",
@@ -1736,9 +1779,9 @@ func TestRunErr(t *testing.T) {
 				_ register,
 				_ [NumRegisters]int64,
 			) (int64, error) {
-				return 0, errors.New("host call handler error")
+				return 0, errHostCallHandler
 			},
-			expected: errors.New("host call handler error"),
+			expected: errHostCallHandler,
 		},
 		{
 			name: "host call handler not set",
@@ -1747,7 +1790,7 @@ func TestRunErr(t *testing.T) {
 				byte(OpcodeHostCall), 0, 0, 0, 0, 0, 0, 0, 0, 1, 0,
 			},
 			hostCallHandler: nil,
-			expected:        errors.New("host call handler not set"),
+			expected:        ErrHostCallHandlerNotSet,
 		},
 	}
 
@@ -1760,13 +1803,13 @@ func TestRunErr(t *testing.T) {
 				WithMagicHeader([]byte{0x00}),
 				WithHostCallHandler(test.hostCallHandler),
 			)
-			err := vm.Run()
+			_, err := vm.Run()
 
 			if err == nil {
 				t.Fatalf("expected error, got nil")
 			}
 
-			if err.Error() != test.expected.Error() {
+			if !errors.Is(err, test.expected) {
 				t.Fatalf(
 					"expected error to be \"%s\", got \"%s\"",
 					test.expected.Error(),
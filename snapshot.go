@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// snapshotMagic is the magic number at the start of every snapshot.
+var snapshotMagic = []byte("VEEMSNAP")
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion uint16 = 1
+
+const (
+	snapshotFlagIsZero byte = 1 << iota
+	snapshotFlagIsNegative
+	snapshotFlagHalted
+)
+
+// Snapshot serializes the full machine state into a versioned binary format,
+// so that execution can be resumed later with Restore.
+//
+// The snapshot contains the program counter, registers, flags, stack,
+// call stack, heap, remaining fuel, exit status, instruction count
+// and a hash of the program.
+// The program itself, the options and the handlers are not included.
+// Only the non-zero parts of the heap are stored.
+func (v *VM) Snapshot() ([]byte, error) {
+	programHash := sha256.Sum256(v.program)
+
+	data := make([]byte, 0, 512+(v.sp*8))
+	data = append(data, snapshotMagic...)
+	data = binary.BigEndian.AppendUint16(data, snapshotVersion)
+	data = append(data, programHash[:]...)
+
+	data = binary.BigEndian.AppendUint64(data, v.pc)
+	data = append(data, v.snapshotFlags())
+
+	data = binary.BigEndian.AppendUint64(data, NumRegisters)
+
+	for _, val := range v.registers {
+		data = binary.BigEndian.AppendUint64(data, uint64(val)) // #nosec: G115
+	}
+
+	data = binary.BigEndian.AppendUint64(data, uint64(len(v.stack)))
+	data = binary.BigEndian.AppendUint64(data, v.sp)
+
+	for _, val := range v.stack[:v.sp] {
+		data = binary.BigEndian.AppendUint64(data, uint64(val)) // #nosec: G115
+	}
+
+	data = binary.BigEndian.AppendUint64(data, v.callStackSize)
+	data = binary.BigEndian.AppendUint64(data, uint64(len(v.callStack)))
+
+	for _, frame := range v.callStack {
+		data = binary.BigEndian.AppendUint64(data, frame.CallSite)
+		data = binary.BigEndian.AppendUint64(data, frame.Target)
+		data = binary.BigEndian.AppendUint64(data, frame.ReturnAddress)
+	}
+
+	data = binary.BigEndian.AppendUint64(data, v.heapSize)
+	data = v.appendHeapRuns(data)
+
+	data = binary.BigEndian.AppendUint64(data, v.fuel)
+	data = binary.BigEndian.AppendUint64(data, uint64(v.exitStatus)) // #nosec: G115
+	data = binary.BigEndian.AppendUint64(data, v.instructionCount)
+
+	return data, nil
+}
+
+func (v *VM) snapshotFlags() byte {
+	var f byte
+
+	if v.flags.isZero {
+		f |= snapshotFlagIsZero
+	}
+
+	if v.flags.isNegative {
+		f |= snapshotFlagIsNegative
+	}
+
+	if v.halted {
+		f |= snapshotFlagHalted
+	}
+
+	return f
+}
+
+// appendHeapRuns appends the runs of non-zero values in the heap,
+// prefixed by the number of runs.
+func (v *VM) appendHeapRuns(data []byte) []byte {
+	numRunsOffset := len(data)
+	data = binary.BigEndian.AppendUint64(data, 0)
+
+	numRuns := uint64(0)
+	runStart := uint64(0)
+	var run []int64
+
+	for addr := uint64(0); addr < v.heapSize; addr++ {
+		if v.heapPages[addr/heapPageSize] == nil {
+			// Skip the rest of the page, since it only contains zeroes.
+			addr |= heapPageMask
+		} else if val := v.loadHeap(addr); val != 0 {
+			if len(run) == 0 {
+				runStart = addr
+			}
+
+			run = append(run, val)
+
+			continue
+		}
+
+		if len(run) != 0 {
+			data = appendHeapRun(data, runStart, run)
+			run = run[:0]
+			numRuns++
+		}
+	}
+
+	if len(run) != 0 {
+		data = appendHeapRun(data, runStart, run)
+		numRuns++
+	}
+
+	binary.BigEndian.PutUint64(data[numRunsOffset:], numRuns)
+
+	return data
+}
+
+func appendHeapRun(data []byte, addr uint64, values []int64) []byte {
+	data = binary.BigEndian.AppendUint64(data, addr)
+	data = binary.BigEndian.AppendUint64(data, uint64(len(values)))
+
+	for _, val := range values {
+		data = binary.BigEndian.AppendUint64(data, uint64(val)) // #nosec: G115
+	}
+
+	return data
+}
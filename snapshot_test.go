@@ -0,0 +1,137 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 42,
+		byte(OpcodePush), 0,
+		byte(OpcodeStoreMemory), 0, 0,
+		byte(OpcodeSub), 1, 1, 0,
+		byte(OpcodeAdd), 2, 0, 0,
+	}
+
+	vm := New(program, WithFuel(100, nil))
+
+	for range 4 {
+		if _, err := vm.Step(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	snapshot, err := vm.Snapshot()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if _, err = vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	restored := New(program, WithFuel(0, nil))
+
+	if err = restored.Restore(snapshot); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if restored.pc != 19 || restored.sp != 1 || restored.stack[0] != 42 || restored.loadHeap(42) != 42 {
+		t.Fatalf(
+			"expected the state to be restored, got pc %d, sp %d, stack %v, heap %d",
+			restored.pc,
+			restored.sp,
+			restored.StackView(),
+			restored.loadHeap(42),
+		)
+	}
+
+	if !restored.flags.isNegative || restored.fuel != 96 || restored.registers[1] != -42 {
+		t.Fatalf(
+			"expected the state to be restored, got flags %v, fuel %d, registers %v",
+			restored.flags,
+			restored.fuel,
+			restored.registers,
+		)
+	}
+
+	if _, err = restored.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if restored.registers != vm.registers || restored.flags != vm.flags {
+		t.Fatalf("expected resuming from a snapshot to end in the same state")
+	}
+}
+
+func TestRestoreErr(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{byte(OpcodeNop)}
+
+	snapshot, err := New(program).Snapshot()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		vm       *VM
+		snapshot []byte
+		expected error
+	}{
+		{
+			name:     "program mismatch",
+			vm:       New([]byte{byte(OpcodeHalt)}),
+			snapshot: snapshot,
+			expected: ErrProgramMismatch,
+		},
+		{
+			name:     "heap size mismatch",
+			vm:       New(program, WithHeapSize(1)),
+			snapshot: snapshot,
+			expected: ErrInvalidSnapshot,
+		},
+		{
+			name:     "truncated",
+			vm:       New(program),
+			snapshot: snapshot[:len(snapshot)-1],
+			expected: ErrInvalidSnapshot,
+		},
+		{
+			name:     "trailing data",
+			vm:       New(program),
+			snapshot: append(append([]byte{}, snapshot...), 0),
+			expected: ErrInvalidSnapshot,
+		},
+		{
+			name:     "unsupported version",
+			vm:       New(program),
+			snapshot: append(append([]byte("VEEMSNAP"), 0xFF, 0xFF), snapshot[10:]...),
+			expected: ErrInvalidSnapshot,
+		},
+		{
+			name:     "missing magic number",
+			vm:       New(program),
+			snapshot: []byte{},
+			expected: ErrInvalidSnapshot,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.vm.Restore(test.snapshot)
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+		})
+	}
+}
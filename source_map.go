@@ -0,0 +1,156 @@
+package vm
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SourceMapping maps the instruction at an address to a line of source code,
+// such as the assembly source that the instruction has been assembled from.
+type SourceMapping struct {
+	// The absolute address of the instruction in the program.
+	Address uint64
+	// The path of the source file.
+	File string
+	// The line in the source file, starting at 1.
+	Line uint64
+}
+
+// SourceMap defines a set of source mappings, which is used to show the
+// source code of a program while debugging it.
+// A nil *SourceMap is valid, and contains no mappings.
+type SourceMap struct {
+	// The mappings, sorted by address.
+	mappings []SourceMapping
+}
+
+// NewSourceMap creates a new source map.
+func NewSourceMap(mappings []SourceMapping) *SourceMap {
+	sorted := slices.Clone(mappings)
+
+	slices.SortStableFunc(sorted, func(a, b SourceMapping) int {
+		return cmp.Compare(a.Address, b.Address)
+	})
+
+	return &SourceMap{mappings: sorted}
+}
+
+// Mappings returns a copy of the mappings, sorted by address.
+func (m *SourceMap) Mappings() []SourceMapping {
+	if m == nil {
+		return nil
+	}
+
+	return slices.Clone(m.mappings)
+}
+
+// Lookup returns the mapping of the instruction that starts at addr.
+func (m *SourceMap) Lookup(addr uint64) (SourceMapping, bool) {
+	if m == nil {
+		return SourceMapping{Address: 0, File: "", Line: 0}, false
+	}
+
+	i, isFound := slices.BinarySearchFunc(
+		m.mappings,
+		addr,
+		func(s SourceMapping, addr uint64) int {
+			return cmp.Compare(s.Address, addr)
+		},
+	)
+
+	if !isFound {
+		return SourceMapping{Address: 0, File: "", Line: 0}, false
+	}
+
+	return m.mappings[i], true
+}
+
+// Addresses returns the addresses of the instructions that have been mapped
+// to a line in a file, sorted by address.
+func (m *SourceMap) Addresses(file string, line uint64) []uint64 {
+	var addrs []uint64
+
+	for _, mapping := range m.Mappings() {
+		if mapping.File == file && mapping.Line == line {
+			addrs = append(addrs, mapping.Address)
+		}
+	}
+
+	return addrs
+}
+
+// ReadSourceMap reads a source map in the text format that is written by
+// WriteTo. Every line contains an address, a line number and a file path,
+// separated by whitespace. The file path is the rest of the line,
+// so it may contain single spaces. Addresses can be decimal, or hexadecimal with a
+// 0x prefix. Empty lines and lines starting with # are ignored.
+func ReadSourceMap(r io.Reader) (*SourceMap, error) {
+	var mappings []SourceMapping
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected an address, a line and a file", lineNum)
+		}
+
+		addr, err := strconv.ParseUint(fields[0], 0, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %w", lineNum, err)
+		}
+
+		sourceLine, err := strconv.ParseUint(fields[1], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid line: %w", lineNum, err)
+		}
+
+		mappings = append(mappings, SourceMapping{
+			Address: addr,
+			File:    strings.Join(fields[2:], " "),
+			Line:    sourceLine,
+		})
+	}
+
+	err := scanner.Err()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read source map: %w", err)
+	}
+
+	return NewSourceMap(mappings), nil
+}
+
+// WriteTo writes the source map in a text format,
+// with a hexadecimal address, a line number and a file path on every line.
+func (m *SourceMap) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	for _, mapping := range m.Mappings() {
+		fmt.Fprintf(&sb, "0x%04x %d %s\n", mapping.Address, mapping.Line, mapping.File)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	if err != nil {
+		return int64(n), fmt.Errorf("could not write source map: %w", err)
+	}
+
+	return int64(n), nil
+}
@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSourceMap(t *testing.T) {
+	t.Parallel()
+
+	sourceMap := NewSourceMap([]SourceMapping{
+		{Address: 11, File: "main.s", Line: 4},
+		{Address: 1, File: "main.s", Line: 2},
+		{Address: 21, File: "main.s", Line: 4},
+	})
+
+	if mapping, found := sourceMap.Lookup(11); !found || mapping.Line != 4 {
+		t.Fatalf("expected address 11 to be on line 4, got %+v", mapping)
+	}
+
+	if _, found := sourceMap.Lookup(12); found {
+		t.Fatalf("expected address 12 not to be mapped")
+	}
+
+	if addrs := sourceMap.Addresses("main.s", 4); !reflect.DeepEqual(addrs, []uint64{11, 21}) {
+		t.Fatalf("expected line 4 to be at [11 21], got %v", addrs)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := sourceMap.WriteTo(&buf); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if buf.String() != "0x0001 2 main.s\n0x000b 4 main.s\n0x0015 4 main.s\n" {
+		t.Fatalf("unexpected source map text:\n%s", buf.String())
+	}
+
+	read, err := ReadSourceMap(strings.NewReader("# comment\n\n" + buf.String()))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(read.Mappings(), sourceMap.Mappings()) {
+		t.Fatalf("expected %v, got %v", sourceMap.Mappings(), read.Mappings())
+	}
+
+	var nilMap *SourceMap
+
+	if _, found := nilMap.Lookup(1); found || nilMap.Addresses("main.s", 2) != nil {
+		t.Fatalf("expected a nil source map to be empty")
+	}
+}
+
+func TestReadSourceMapErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing file", input: "0x0001 2\n"},
+		{name: "invalid address", input: "main 2 main.s\n"},
+		{name: "invalid line", input: "0x0001 x main.s\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ReadSourceMap(strings.NewReader(test.input)); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
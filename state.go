@@ -0,0 +1,130 @@
+package vm
+
+// Flags defines the flags of the CPU, as exposed to the host.
+type Flags struct {
+	// Whether the result of the last operation was zero.
+	IsZero bool
+	// Whether the result of the last operation was negative.
+	IsNegative bool
+}
+
+// Registers returns a copy of all registers.
+func (v *VM) Registers() [NumRegisters]int64 {
+	return v.registers
+}
+
+// Register returns the value of a single register.
+func (v *VM) Register(index register) (int64, error) {
+	if index >= NumRegisters {
+		return 0, ErrInvalidRegister
+	}
+
+	return v.registers[index], nil
+}
+
+// SetRegister sets the value of a single register.
+func (v *VM) SetRegister(index register, value int64) error {
+	if index >= NumRegisters {
+		return ErrInvalidRegister
+	}
+
+	v.registers[index] = value
+
+	return nil
+}
+
+// PC returns the program counter.
+func (v *VM) PC() register {
+	return v.pc
+}
+
+// SetPC sets the program counter.
+// Setting it to the length of the program will make the VM stop.
+func (v *VM) SetPC(pc register) error {
+	if pc > v.programLen {
+		return ErrOutOfBounds
+	}
+
+	v.pc = pc
+	v.halted = false
+	v.skipBreakpoint = false
+
+	return nil
+}
+
+// Flags returns the current state of the flags register.
+func (v *VM) Flags() Flags {
+	return Flags{
+		IsZero:     v.flags.isZero,
+		IsNegative: v.flags.isNegative,
+	}
+}
+
+// SetFlags sets the state of the flags register.
+func (v *VM) SetFlags(f Flags) {
+	v.flags.isZero = f.IsZero
+	v.flags.isNegative = f.IsNegative
+}
+
+// SP returns the stack pointer.
+func (v *VM) SP() register {
+	return v.sp
+}
+
+// StackView returns a copy of the values that are currently on the stack.
+// The first element is the bottom of the stack.
+func (v *VM) StackView() []int64 {
+	stack := make([]int64, v.sp)
+	copy(stack, v.stack[:v.sp])
+
+	return stack
+}
+
+// SetStack replaces the contents of the stack, and moves the stack pointer
+// to just above the last value.
+// The first element is the bottom of the stack.
+func (v *VM) SetStack(values []int64) error {
+	if uint64(len(values)) > uint64(len(v.stack)) {
+		return ErrStackOverflow
+	}
+
+	copy(v.stack, values)
+	v.sp = register(len(values))
+
+	return nil
+}
+
+// HeapSize returns the number of values that fit in the heap.
+func (v *VM) HeapSize() uint64 {
+	return v.heapSize
+}
+
+// ReadHeap returns a copy of n values from the heap, starting at addr.
+func (v *VM) ReadHeap(addr register, n uint64) ([]int64, error) {
+	if addr > v.heapSize || n > v.heapSize-addr {
+		return nil, ErrOutOfBounds
+	}
+
+	values := make([]int64, n)
+
+	for i := range values {
+		values[i] = v.loadHeap(addr + uint64(i))
+	}
+
+	return values, nil
+}
+
+// WriteHeap writes values to the heap, starting at addr.
+func (v *VM) WriteHeap(addr register, values []int64) error {
+	n := uint64(len(values))
+
+	if addr > v.heapSize || n > v.heapSize-addr {
+		return ErrOutOfBounds
+	}
+
+	for i, val := range values {
+		v.storeHeap(addr+uint64(i), val)
+	}
+
+	return nil
+}
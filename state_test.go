@@ -0,0 +1,141 @@
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodePop), 1,
+		byte(OpcodeAdd), 2, 0, 1,
+		byte(OpcodeLoadImmediate), 3, 0, 0, 0, 0, 0, 0, 0, 10,
+		byte(OpcodeStoreMemory), 2, 3,
+	}
+
+	vm := New(program)
+
+	if err := vm.SetRegister(0, 5); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if err := vm.SetStack([]int64{7}); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	vm.SetFlags(Flags{IsZero: true, IsNegative: true})
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.Registers() != [NumRegisters]int64{5, 7, 12, 10} {
+		t.Fatalf("expected registers to be [5 7 12 10 ...], got %v", vm.Registers())
+	}
+
+	if val, _ := vm.Register(2); val != 12 {
+		t.Fatalf("expected register 2 to be 12, got %d", val)
+	}
+
+	if vm.Flags() != (Flags{IsZero: false, IsNegative: false}) {
+		t.Fatalf("expected flags to be cleared, got %v", vm.Flags())
+	}
+
+	if vm.PC() != register(len(program)) {
+		t.Fatalf("expected pc to be %d, got %d", len(program), vm.PC())
+	}
+
+	if vm.SP() != 0 || len(vm.StackView()) != 0 {
+		t.Fatalf("expected the stack to be empty, got %v", vm.StackView())
+	}
+
+	heap, err := vm.ReadHeap(9, 3)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(heap, []int64{0, 12, 0}) {
+		t.Fatalf("expected heap to be [0 12 0], got %v", heap)
+	}
+
+	if vm.HeapSize() != HeapSize {
+		t.Fatalf("expected heap size to be %d, got %d", HeapSize, vm.HeapSize())
+	}
+
+	if err := vm.WriteHeap(HeapSize-2, []int64{1, 2}); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+}
+
+func TestStateErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fn       func(vm *VM) error
+		expected error
+	}{
+		{
+			name: "register out of bounds",
+			fn: func(vm *VM) error {
+				_, err := vm.Register(NumRegisters)
+
+				return err
+			},
+			expected: ErrInvalidRegister,
+		},
+		{
+			name: "set register out of bounds",
+			fn: func(vm *VM) error {
+				return vm.SetRegister(NumRegisters, 1)
+			},
+			expected: ErrInvalidRegister,
+		},
+		{
+			name: "set pc out of bounds",
+			fn: func(vm *VM) error {
+				return vm.SetPC(2)
+			},
+			expected: ErrOutOfBounds,
+		},
+		{
+			name: "set stack overflow",
+			fn: func(vm *VM) error {
+				return vm.SetStack(make([]int64, StackSize+1))
+			},
+			expected: ErrStackOverflow,
+		},
+		{
+			name: "read heap out of bounds",
+			fn: func(vm *VM) error {
+				_, err := vm.ReadHeap(HeapSize-1, 2)
+
+				return err
+			},
+			expected: ErrOutOfBounds,
+		},
+		{
+			name: "write heap out of bounds",
+			fn: func(vm *VM) error {
+				return vm.WriteHeap(HeapSize, []int64{1})
+			},
+			expected: ErrOutOfBounds,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.fn(New([]byte{byte(OpcodeNop)}))
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package vm
+
+import (
+	"errors"
+)
+
+// StepInfo describes a single instruction that has been executed.
+type StepInfo struct {
+	// The opcode of the executed instruction.
+	Opcode Opcode
+	// The program counter at the start of the instruction.
+	PC register
+	// The length of the instruction in bytes, including the opcode itself.
+	Len uint64
+	// The registers that the instruction wrote to.
+	// The slice is only valid until the next call to Step.
+	WrittenRegisters []register
+	// Whether the VM has stopped after executing the instruction.
+	Halted bool
+}
+
+// Step executes exactly one instruction.
+//
+// When the VM has already stopped, no instruction is executed,
+// and the returned StepInfo has Halted set to true.
+//
+// Step does not stop at breakpoints, and a following Run or RunContext
+// does not pause at a breakpoint at the instruction that Step stopped at.
+func (v *VM) Step() (StepInfo, error) {
+	err := v.validateMagicHeader()
+
+	if err == nil {
+		err = v.heapImageErr
+	}
+
+	if err != nil {
+		return StepInfo{
+			Opcode:           OpcodeNop,
+			PC:               v.pc,
+			Len:              0,
+			WrittenRegisters: nil,
+			Halted:           false,
+		}, err
+	}
+
+	info, err := v.step()
+
+	// The VM is now stopped at the next instruction, so resuming should
+	// execute it, even when there is a breakpoint at it.
+	// An instruction that ran out of fuel has been rolled back,
+	// so the VM is still stopped where it was.
+	if !errors.Is(err, ErrOutOfFuel) {
+		v.skipBreakpoint = err == nil
+	}
+
+	return info, err
+}
+
+func (v *VM) step() (StepInfo, error) {
+	v.watchpointHit.Reason = PauseReasonNone
+
+	if len(v.traceHooks) != 0 {
+		return v.stepTraced()
+	}
+
+	return v.stepUntraced()
+}
+
+func (v *VM) stepUntraced() (StepInfo, error) {
+	instructionStart := v.pc
+
+	if instructionStart >= v.programLen {
+		return StepInfo{
+			Opcode:           OpcodeNop,
+			PC:               instructionStart,
+			Len:              0,
+			WrittenRegisters: nil,
+			Halted:           true,
+		}, nil
+	}
+
+	opcode := v.decodeInstruction()
+
+	instructionLen := GetInstructionLen(opcode)
+	instructionEnd := instructionStart + instructionLen
+
+	info := StepInfo{
+		Opcode:           opcode,
+		PC:               instructionStart,
+		Len:              instructionLen,
+		WrittenRegisters: nil,
+		Halted:           false,
+	}
+
+	var undo undoRecord
+
+	if v.recording {
+		undo = v.prepareUndo(opcode, instructionStart, instructionEnd)
+	}
+
+	// The fuel before the instruction, which is restored on a rollback,
+	// since the host call handler may have consumed fuel as well.
+	fuelBefore := v.fuel
+
+	if v.fuelEnabled {
+		cost := v.getFuelCost(opcode)
+
+		if cost > v.fuel {
+			return info, ErrOutOfFuel
+		}
+
+		v.fuel -= cost
+	}
+
+	v.pc += instructionLen
+
+	err := v.executeInstruction(opcode, instructionStart, instructionEnd)
+
+	if err != nil {
+		if errors.Is(err, ErrOutOfFuel) {
+			// Roll back the instruction, so it gets retried when resuming.
+			v.pc = instructionStart
+			v.fuel = fuelBefore
+
+			return info, err
+		}
+
+		return info, v.wrapFault(err, opcode, instructionStart, instructionEnd)
+	}
+
+	v.instructionCount++
+
+	if v.recording {
+		v.pushUndo(undo)
+	}
+
+	info.WrittenRegisters = v.writtenRegisters(opcode, instructionStart)
+	info.Halted = v.pc >= v.programLen
+
+	return info, nil
+}
@@ -0,0 +1,72 @@
+package vm
+
+// RecordedSteps returns the number of instructions that can be undone
+// with StepBack.
+func (v *VM) RecordedSteps() uint64 {
+	return v.undoCount
+}
+
+// StepBack undoes the last executed instruction,
+// and returns the StepInfo of that instruction.
+// It requires WithRecording, and returns ErrNoHistory when there is nothing
+// left to undo.
+//
+// The registers, flags, stack pointer, call stack, heap and exit status are
+// restored. Fuel that has been consumed is not refunded,
+// and the effects of host calls on the host cannot be undone.
+// Changes that the host made with SetRegister, WriteHeap and the like
+// are not recorded, and are not undone either.
+func (v *VM) StepBack() (StepInfo, error) {
+	record, hasRecord := v.popUndo()
+
+	if !hasRecord {
+		return StepInfo{
+			Opcode:           OpcodeNop,
+			PC:               v.pc,
+			Len:              0,
+			WrittenRegisters: nil,
+			Halted:           v.pc >= v.programLen,
+		}, ErrNoHistory
+	}
+
+	v.applyUndo(record)
+
+	// The VM is now stopped at the undone instruction, so resuming should
+	// execute it, even when there is a breakpoint at it.
+	v.skipBreakpoint = true
+
+	opcode := v.decodeInstruction()
+
+	return StepInfo{
+		Opcode:           opcode,
+		PC:               v.pc,
+		Len:              GetInstructionLen(opcode),
+		WrittenRegisters: nil,
+		Halted:           false,
+	}, nil
+}
+
+// RunBackTo undoes instructions until the program counter is at pc,
+// and returns the number of undone instructions.
+// At least one instruction is undone, so calling it repeatedly walks back
+// through every earlier execution of the instruction at pc.
+//
+// When the recorded history runs out before pc is reached,
+// the VM is left at the oldest recorded instruction, with ErrNoHistory.
+func (v *VM) RunBackTo(pc register) (uint64, error) {
+	var undone uint64
+
+	for {
+		_, err := v.StepBack()
+
+		if err != nil {
+			return undone, err
+		}
+
+		undone++
+
+		if v.pc == pc {
+			return undone, nil
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// observableState is the state of a VM that StepBack restores.
+type observableState struct {
+	PC               register
+	Registers        [NumRegisters]int64
+	Flags            Flags
+	Stack            []int64
+	Heap             []int64
+	CallFrames       []CallFrame
+	ExitStatus       int64
+	InstructionCount uint64
+}
+
+func observe(t *testing.T, vm *VM) observableState {
+	t.Helper()
+
+	heap, err := vm.ReadHeap(0, 8)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	return observableState{
+		PC:               vm.PC(),
+		Registers:        vm.Registers(),
+		Flags:            vm.Flags(),
+		Stack:            vm.StackView(),
+		Heap:             heap,
+		CallFrames:       vm.CallFrames(),
+		ExitStatus:       vm.ExitStatus(),
+		InstructionCount: vm.InstructionCount(),
+	}
+}
+
+func TestStepBack(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 5,
+		byte(OpcodePush), 0,
+		byte(OpcodePop), 1,
+		byte(OpcodeLoadImmediate), 2, 0, 0, 0, 0, 0, 0, 0, 3,
+		byte(OpcodeStoreMemory), 0, 2,
+		byte(OpcodeCallImmediate), 0, 0, 0, 0, 0, 0, 0, 38,
+		byte(OpcodeHaltRegister), 1,
+		byte(OpcodeCMP), 0, 1,
+		byte(OpcodeReturn),
+	}
+
+	vm := New(program, WithRecording(0))
+	states := []observableState{observe(t, vm)}
+
+	for vm.PC() < register(len(program)) {
+		if _, err := vm.Step(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		states = append(states, observe(t, vm))
+	}
+
+	if vm.RecordedSteps() != 9 || vm.ExitStatus() != 5 {
+		t.Fatalf("expected 9 recorded steps and exit status 5, got %d and %d", vm.RecordedSteps(), vm.ExitStatus())
+	}
+
+	for i := len(states) - 2; i >= 0; i-- {
+		info, err := vm.StepBack()
+
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if info.PC != states[i].PC || info.Opcode != Opcode(program[info.PC]) {
+			t.Fatalf("expected to step back to pc %d, got %+v", states[i].PC, info)
+		}
+
+		if state := observe(t, vm); !reflect.DeepEqual(state, states[i]) {
+			t.Fatalf("expected state %d to be %+v, got %+v", i, states[i], state)
+		}
+	}
+
+	if _, err := vm.StepBack(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrNoHistory, err)
+	}
+
+	if _, err := vm.Run(); err != nil || !reflect.DeepEqual(observe(t, vm), states[len(states)-1]) {
+		t.Fatalf("expected running again to end in the same state")
+	}
+}
+
+func TestStepBackPopPush(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodePop), 1,
+		byte(OpcodePush), 2,
+	}
+
+	vm := New(program, WithRecording(0))
+
+	if err := vm.SetStack([]int64{10, 20}); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if err := vm.SetRegister(2, 99); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	for range 2 {
+		if _, err := vm.StepBack(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	if stack := vm.StackView(); !reflect.DeepEqual(stack, []int64{10, 20}) {
+		t.Fatalf("expected the stack to be restored to [10 20], got %v", stack)
+	}
+}
+
+func TestRunBackTo(t *testing.T) {
+	t.Parallel()
+
+	// Store 3, 2 and 1 at heap address 0, in a loop.
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 3,
+		byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodeStoreMemory), 0, 2,
+		byte(OpcodeSub), 0, 0, 1,
+		byte(OpcodeJmpImmediateIfNotZero), 0, 0, 0, 0, 0, 0, 0, 0, 20,
+	}
+
+	vm := New(program, WithRecording(0))
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	for _, expected := range []int64{1, 2, 3} {
+		if _, err := vm.RunBackTo(20); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if value, _ := vm.Register(0); value != expected {
+			t.Fatalf("expected the store of %d, got %d", expected, value)
+		}
+	}
+
+	if heap, _ := vm.ReadHeap(0, 1); heap[0] != 0 {
+		t.Fatalf("expected the heap to be empty before the first store, got %d", heap[0])
+	}
+
+	undone, err := vm.RunBackTo(20)
+
+	if !errors.Is(err, ErrNoHistory) || undone != 2 || vm.PC() != 0 {
+		t.Fatalf("expected to run out of history at pc 0, got %d undone at pc %d", undone, vm.PC())
+	}
+}
+
+func TestRecordingLimit(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodeAdd), 0, 0, 0,
+		byte(OpcodeAdd), 0, 0, 0,
+		byte(OpcodeAdd), 0, 0, 0,
+	}
+
+	vm := New(program, WithRecording(2))
+
+	for range 3 {
+		if _, err := vm.Step(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+	}
+
+	if _, err := vm.StepBack(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if vm.RecordedSteps() != 2 {
+		t.Fatalf("expected 2 recorded steps, got %d", vm.RecordedSteps())
+	}
+
+	for _, expected := range []int64{4, 2} {
+		if _, err := vm.StepBack(); err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if value, _ := vm.Register(0); value != expected {
+			t.Fatalf("expected register 0 to be %d, got %d", expected, value)
+		}
+	}
+
+	if _, err := vm.StepBack(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrNoHistory, err)
+	}
+
+	if _, err := New(program).StepBack(); !errors.Is(err, ErrNoHistory) {
+		t.Fatalf("expected error to be \"%v\" without recording, got \"%v\"", ErrNoHistory, err)
+	}
+}
@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStep(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		0x00,
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodePush), 0,
+		byte(OpcodeAdd), 2, 0, 0,
+		byte(OpcodeHalt),
+		byte(OpcodeNop),
+	}
+
+	expected := []StepInfo{
+		{
+			Opcode:           OpcodeLoadImmediate,
+			PC:               1,
+			Len:              10,
+			WrittenRegisters: []register{0},
+			Halted:           false,
+		},
+		{
+			Opcode:           OpcodePush,
+			PC:               11,
+			Len:              2,
+			WrittenRegisters: nil,
+			Halted:           false,
+		},
+		{
+			Opcode:           OpcodeAdd,
+			PC:               13,
+			Len:              4,
+			WrittenRegisters: []register{2},
+			Halted:           false,
+		},
+		{
+			Opcode:           OpcodeHalt,
+			PC:               17,
+			Len:              1,
+			WrittenRegisters: nil,
+			Halted:           true,
+		},
+		{
+			Opcode:           OpcodeNop,
+			PC:               19,
+			Len:              0,
+			WrittenRegisters: nil,
+			Halted:           true,
+		},
+	}
+
+	vm := New(program, WithMagicHeader([]byte{0x00}))
+
+	for i, expectedInfo := range expected {
+		info, err := vm.Step()
+
+		if err != nil {
+			t.Fatalf("expected no error, got %s", err.Error())
+		}
+
+		if !reflect.DeepEqual(info, expectedInfo) {
+			t.Fatalf("expected step %d to be %+v, got %+v", i, expectedInfo, info)
+		}
+	}
+
+	runVM := New(program, WithMagicHeader([]byte{0x00}))
+
+	if _, err := runVM.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if runVM.registers != vm.registers || runVM.pc != vm.pc || runVM.sp != vm.sp {
+		t.Fatalf("expected Run and Step to end in the same state")
+	}
+}
+
+func TestStepErr(t *testing.T) {
+	t.Parallel()
+
+	vm := New([]byte{0xFF, byte(OpcodeNop)}, WithMagicHeader([]byte{0x00}))
+
+	if _, err := vm.Step(); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	vm = New([]byte{byte(OpcodeDiv), 0, 0, 0})
+
+	info, err := vm.Step()
+
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if info.Opcode != OpcodeDiv || info.PC != 0 || info.WrittenRegisters != nil {
+		t.Fatalf("expected the faulting instruction to be reported, got %+v", info)
+	}
+}
@@ -0,0 +1,148 @@
+package vm
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Symbol defines a named address in a program, such as a function or label.
+type Symbol struct {
+	// The name of the symbol.
+	Name string
+	// The absolute address of the symbol in the program.
+	Address uint64
+}
+
+// SymbolTable defines a set of symbols, which is used to give addresses
+// in a program a human-readable name.
+// A nil *SymbolTable is valid, and contains no symbols.
+type SymbolTable struct {
+	// The symbols, sorted by address.
+	symbols []Symbol
+}
+
+// NewSymbolTable creates a new symbol table.
+func NewSymbolTable(symbols []Symbol) *SymbolTable {
+	sorted := slices.Clone(symbols)
+
+	slices.SortStableFunc(sorted, func(a, b Symbol) int {
+		return cmp.Compare(a.Address, b.Address)
+	})
+
+	return &SymbolTable{symbols: sorted}
+}
+
+// Symbols returns a copy of the symbols, sorted by address.
+func (t *SymbolTable) Symbols() []Symbol {
+	if t == nil {
+		return nil
+	}
+
+	return slices.Clone(t.symbols)
+}
+
+// Lookup returns the symbol that contains addr,
+// which is the symbol with the highest address that is not above addr.
+func (t *SymbolTable) Lookup(addr uint64) (Symbol, bool) {
+	if t == nil {
+		return Symbol{Name: "", Address: 0}, false
+	}
+
+	i, isExact := slices.BinarySearchFunc(
+		t.symbols,
+		addr,
+		func(s Symbol, addr uint64) int {
+			return cmp.Compare(s.Address, addr)
+		},
+	)
+
+	if isExact {
+		return t.symbols[i], true
+	}
+
+	if i == 0 {
+		return Symbol{Name: "", Address: 0}, false
+	}
+
+	return t.symbols[i-1], true
+}
+
+// Address returns the address of the symbol with the provided name.
+func (t *SymbolTable) Address(name string) (uint64, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	for _, s := range t.symbols {
+		if s.Name == name {
+			return s.Address, true
+		}
+	}
+
+	return 0, false
+}
+
+// ReadSymbolTable reads a symbol table in the text format that is written by
+// WriteTo. Every line contains an address and a name, separated by
+// whitespace. Addresses can be decimal, or hexadecimal with a 0x prefix.
+// Empty lines and lines starting with # are ignored.
+func ReadSymbolTable(r io.Reader) (*SymbolTable, error) {
+	var symbols []Symbol
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an address and a name", lineNum)
+		}
+
+		addr, err := strconv.ParseUint(fields[0], 0, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %w", lineNum, err)
+		}
+
+		symbols = append(symbols, Symbol{Name: fields[1], Address: addr})
+	}
+
+	err := scanner.Err()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read symbol table: %w", err)
+	}
+
+	return NewSymbolTable(symbols), nil
+}
+
+// WriteTo writes the symbol table in a text format,
+// with a hexadecimal address and a name on every line.
+func (t *SymbolTable) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	for _, s := range t.Symbols() {
+		fmt.Fprintf(&sb, "0x%04x %s\n", s.Address, s.Name)
+	}
+
+	n, err := io.WriteString(w, sb.String())
+
+	if err != nil {
+		return int64(n), fmt.Errorf("could not write symbol table: %w", err)
+	}
+
+	return int64(n), nil
+}
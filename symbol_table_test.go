@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSymbolTable(t *testing.T) {
+	t.Parallel()
+
+	table := NewSymbolTable([]Symbol{
+		{Name: "loop", Address: 20},
+		{Name: "main", Address: 1},
+	})
+
+	tests := []struct {
+		addr     uint64
+		expected string
+		found    bool
+	}{
+		{addr: 0, expected: "", found: false},
+		{addr: 1, expected: "main", found: true},
+		{addr: 19, expected: "main", found: true},
+		{addr: 20, expected: "loop", found: true},
+		{addr: 100, expected: "loop", found: true},
+	}
+
+	for _, test := range tests {
+		symbol, found := table.Lookup(test.addr)
+
+		if symbol.Name != test.expected || found != test.found {
+			t.Fatalf("expected address %d to be in \"%s\", got \"%s\"", test.addr, test.expected, symbol.Name)
+		}
+	}
+
+	if addr, _ := table.Address("loop"); addr != 20 {
+		t.Fatalf("expected \"loop\" to be at address 20, got %d", addr)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if buf.String() != "0x0001 main\n0x0014 loop\n" {
+		t.Fatalf("unexpected symbol table text:\n%s", buf.String())
+	}
+
+	read, err := ReadSymbolTable(strings.NewReader("# comment\n\n" + buf.String()))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(read.Symbols(), table.Symbols()) {
+		t.Fatalf("expected %v, got %v", table.Symbols(), read.Symbols())
+	}
+
+	if _, err = ReadSymbolTable(strings.NewReader("main")); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	var nilTable *SymbolTable
+
+	if _, found := nilTable.Lookup(1); found {
+		t.Fatalf("expected a nil symbol table to be empty")
+	}
+}
@@ -0,0 +1,117 @@
+package vm
+
+import "errors"
+
+// TraceEvent describes an instruction that is about to be executed,
+// or that has just been executed.
+type TraceEvent struct {
+	// The number of instructions that have been executed before this one.
+	Step uint64
+	// The program counter at the start of the instruction.
+	PC register
+	// The opcode of the instruction.
+	Opcode Opcode
+	// The decoded operands of the instruction.
+	// This is nil when the instruction cannot be decoded.
+	Operands []Operand
+	// A copy of the registers.
+	Registers [NumRegisters]int64
+	// The state of the flags register.
+	Flags Flags
+	// The stack pointer.
+	SP register
+	// The program counter after the instruction.
+	// This is only set after the instruction has been executed.
+	NextPC register
+	// The error that the instruction returned.
+	// This is only set after the instruction has been executed.
+	Err error
+}
+
+// TraceHook defines callbacks that are called for every instruction.
+// Either callback may be nil.
+//
+// An instruction that runs out of fuel is rolled back without being executed,
+// so After is not called for it.
+// Before is called again when the instruction is retried after adding fuel.
+//
+// The event must not be retained after the callback returns.
+// When a callback returns an error, execution stops with that error.
+type TraceHook struct {
+	// Before is called before an instruction is executed.
+	Before func(event *TraceEvent) error
+	// After is called after an instruction has been executed.
+	After func(event *TraceEvent) error
+}
+
+// WithTraceHook adds a trace hook to the VM.
+// Multiple trace hooks are called in the order in which they were added.
+func WithTraceHook(hook TraceHook) Option {
+	return func(v *VM) {
+		v.traceHooks = append(v.traceHooks, hook)
+	}
+}
+
+func (v *VM) stepTraced() (StepInfo, error) {
+	if v.pc >= v.programLen {
+		return v.stepUntraced()
+	}
+
+	operands, _ := DecodeOperands(v.program, v.pc)
+
+	event := &TraceEvent{
+		Step:      v.instructionCount,
+		PC:        v.pc,
+		Opcode:    v.decodeInstruction(),
+		Operands:  operands,
+		Registers: v.registers,
+		Flags:     v.Flags(),
+		SP:        v.sp,
+		NextPC:    0,
+		Err:       nil,
+	}
+
+	for _, hook := range v.traceHooks {
+		if hook.Before == nil {
+			continue
+		}
+
+		err := hook.Before(event)
+
+		if err != nil {
+			return StepInfo{
+				Opcode:           event.Opcode,
+				PC:               event.PC,
+				Len:              GetInstructionLen(event.Opcode),
+				WrittenRegisters: nil,
+				Halted:           false,
+			}, err
+		}
+	}
+
+	info, err := v.stepUntraced()
+
+	if errors.Is(err, ErrOutOfFuel) {
+		return info, err
+	}
+
+	event.Registers = v.registers
+	event.Flags = v.Flags()
+	event.SP = v.sp
+	event.NextPC = v.pc
+	event.Err = err
+
+	for _, hook := range v.traceHooks {
+		if hook.After == nil {
+			continue
+		}
+
+		hookErr := hook.After(event)
+
+		if hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+
+	return info, err
+}
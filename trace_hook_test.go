@@ -0,0 +1,132 @@
+package vm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTraceHook(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		byte(OpcodeSub), 1, 1, 0,
+	}
+
+	var before, after []TraceEvent
+
+	vm := New(program, WithTraceHook(TraceHook{
+		Before: func(event *TraceEvent) error {
+			before = append(before, *event)
+
+			return nil
+		},
+		After: func(event *TraceEvent) error {
+			after = append(after, *event)
+
+			return nil
+		},
+	}))
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if len(before) != 2 || len(after) != 2 {
+		t.Fatalf("expected 2 events before and after, got %d and %d", len(before), len(after))
+	}
+
+	expectedOperands := []Operand{
+		{Kind: OperandRegister, Value: 1},
+		{Kind: OperandRegister, Value: 1},
+		{Kind: OperandRegister, Value: 0},
+	}
+
+	if before[1].Step != 1 || before[1].PC != 10 || before[1].Opcode != OpcodeSub {
+		t.Fatalf("expected the second event to be the sub, got %+v", before[1])
+	}
+
+	if !reflect.DeepEqual(before[1].Operands, expectedOperands) {
+		t.Fatalf("expected operands to be %v, got %v", expectedOperands, before[1].Operands)
+	}
+
+	if before[1].Registers[1] != 0 || after[1].Registers[1] != -1 {
+		t.Fatalf("expected registers before and after the instruction, got %v and %v", before[1].Registers, after[1].Registers)
+	}
+
+	if before[1].Flags.IsNegative || !after[1].Flags.IsNegative || after[1].NextPC != 14 {
+		t.Fatalf("expected flags and next pc after the instruction, got %+v", after[1])
+	}
+}
+
+func TestTraceHookErr(t *testing.T) {
+	t.Parallel()
+
+	errAssertion := errors.New("assertion failed")
+
+	vm := New(
+		[]byte{
+			byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 1,
+			byte(OpcodeLoadImmediate), 1, 0, 0, 0, 0, 0, 0, 0, 2,
+		},
+		WithTraceHook(TraceHook{
+			Before: func(event *TraceEvent) error {
+				if event.Registers[0] != 0 {
+					return errAssertion
+				}
+
+				return nil
+			},
+			After: nil,
+		}),
+	)
+
+	result, err := vm.Run()
+
+	if !errors.Is(err, errAssertion) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", errAssertion, err)
+	}
+
+	if vm.PC() != 10 || vm.registers[1] != 0 || result.Instructions != 1 {
+		t.Fatalf("expected the second instruction not to be executed, got pc %d", vm.PC())
+	}
+}
+
+func TestTraceHookOutOfFuel(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeNop),
+		byte(OpcodeNop),
+	}
+
+	var before, after []uint64
+
+	vm := New(program, WithFuel(1, nil), WithTraceHook(TraceHook{
+		Before: func(event *TraceEvent) error {
+			before = append(before, event.Step)
+
+			return nil
+		},
+		After: func(event *TraceEvent) error {
+			after = append(after, event.Step)
+
+			return nil
+		},
+	}))
+
+	if _, err := vm.Run(); !errors.Is(err, ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfFuel, err)
+	}
+
+	vm.AddFuel(1)
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(before, []uint64{0, 1, 1}) || !reflect.DeepEqual(after, []uint64{0, 1}) {
+		t.Fatalf("expected After only for executed instructions, got before %v and after %v", before, after)
+	}
+}
@@ -0,0 +1,199 @@
+package vm
+
+// undoKind defines which part of the state an instruction changed,
+// besides the program counter, stack pointer and flags.
+type undoKind byte
+
+const (
+	// undoNone means that nothing else changed.
+	undoNone undoKind = iota
+	// undoRegister means that the register at addr held value.
+	undoRegister
+	// undoHeap means that the heap address at addr held value.
+	undoHeap
+	// undoStack means that the stack slot at addr held value.
+	undoStack
+	// undoCall means that a call frame has been pushed.
+	undoCall
+	// undoReturn means that the call frame with call site addr and target
+	// value has been popped. Its return address is the program counter
+	// after the instruction.
+	undoReturn
+	// undoHalt means that the exit status was value.
+	undoHalt
+)
+
+// undoRecord holds the minimal information to undo a single instruction.
+type undoRecord struct {
+	// The program counter at the start of the instruction.
+	pc register
+	// The stack pointer before the instruction.
+	sp register
+	// The register index, heap address, stack slot or call site,
+	// depending on kind.
+	addr register
+	// The overwritten value, depending on kind.
+	value int64
+	// Which part of the state the instruction changed.
+	kind undoKind
+	// The flags before the instruction.
+	flags flags
+}
+
+// prepareUndo captures the state that the instruction is about to change.
+func (v *VM) prepareUndo(
+	opcode Opcode,
+	instructionStart register,
+	instructionEnd register,
+) undoRecord {
+	record := undoRecord{
+		pc:    instructionStart,
+		sp:    v.sp,
+		addr:  0,
+		value: 0,
+		kind:  undoNone,
+		flags: v.flags,
+	}
+
+	if instructionEnd > v.programLen {
+		// The instruction is truncated, so it faults without changing anything.
+		return record
+	}
+
+	if offset, hasDestination := destinationRegisterOffsets[opcode]; hasDestination {
+		dest := register(v.program[instructionStart+offset]) & NumRegistersMask
+
+		record.kind = undoRegister
+		record.addr = dest
+		record.value = v.registers[dest]
+
+		return record
+	}
+
+	switch opcode {
+	case OpcodeStoreMemory:
+		addrReg := register(v.program[instructionStart+2]) & NumRegistersMask
+		addr := v.registers[addrReg]
+
+		if addr >= 0 && uint64(addr) < v.heapSize {
+			record.kind = undoHeap
+			record.addr = uint64(addr)
+			record.value = v.loadHeap(uint64(addr))
+		}
+
+	case OpcodePush:
+		if v.sp < uint64(len(v.stack)) {
+			record.kind = undoStack
+			record.addr = v.sp
+			record.value = v.stack[v.sp]
+		}
+
+	case OpcodeCallImmediate, OpcodeCallRegister:
+		record.kind = undoCall
+
+	case OpcodeReturn:
+		if len(v.callStack) != 0 {
+			frame := v.callStack[len(v.callStack)-1]
+
+			record.kind = undoReturn
+			record.addr = frame.CallSite
+			record.value = int64(frame.Target) // #nosec: G115
+		}
+
+	case OpcodeHalt, OpcodeHaltRegister:
+		record.kind = undoHalt
+		record.value = v.exitStatus
+
+	default:
+		// The other instructions only change the program counter,
+		// stack pointer or flags.
+	}
+
+	return record
+}
+
+// applyUndo reverts the instruction that record has been captured for.
+// It must be applied to the state right after the instruction.
+func (v *VM) applyUndo(record undoRecord) {
+	switch record.kind {
+	case undoRegister:
+		v.registers[record.addr] = record.value
+
+	case undoHeap:
+		v.storeHeap(record.addr, record.value)
+
+	case undoStack:
+		v.stack[record.addr] = record.value
+
+	case undoCall:
+		v.callStack = v.callStack[:len(v.callStack)-1]
+
+	case undoReturn:
+		v.callStack = append(v.callStack, CallFrame{
+			CallSite:      record.addr,
+			Target:        register(record.value), // #nosec: G115
+			ReturnAddress: v.pc,
+		})
+
+	case undoHalt:
+		v.exitStatus = record.value
+
+	case undoNone:
+	}
+
+	v.pc = record.pc
+	v.sp = record.sp
+	v.flags = record.flags
+	v.halted = false
+	v.instructionCount--
+}
+
+// pushUndo adds a record to the undo log.
+// When the log is full, the oldest record is dropped.
+func (v *VM) pushUndo(record undoRecord) {
+	capacity := uint64(len(v.undoLog))
+
+	switch {
+	case v.undoCount < capacity:
+		v.undoLog[(v.undoHead+v.undoCount)%capacity] = record
+		v.undoCount++
+
+	case v.undoLimit == 0 || capacity < v.undoLimit:
+		if v.undoHead != 0 {
+			v.undoLog = append(v.undoLog[v.undoHead:], v.undoLog[:v.undoHead]...)
+			v.undoHead = 0
+		}
+
+		v.undoLog = append(v.undoLog, record)
+		v.undoCount++
+
+	default:
+		v.undoLog[v.undoHead] = record
+		v.undoHead = (v.undoHead + 1) % capacity
+	}
+}
+
+// popUndo removes the newest record from the undo log.
+func (v *VM) popUndo() (undoRecord, bool) {
+	if v.undoCount == 0 {
+		return undoRecord{
+			pc:    0,
+			sp:    0,
+			addr:  0,
+			value: 0,
+			kind:  undoNone,
+			flags: flags{isZero: false, isNegative: false},
+		}, false
+	}
+
+	v.undoCount--
+
+	return v.undoLog[(v.undoHead+v.undoCount)%uint64(len(v.undoLog))], true
+}
+
+// clearUndo removes all records from the undo log.
+func (v *VM) clearUndo() {
+	v.undoLog = v.undoLog[:0]
+	v.undoHead = 0
+	v.undoCount = 0
+}
@@ -2,11 +2,8 @@ package vm
 
 import (
 	"bytes"
-	"errors"
 )
 
-var errInvalidMagicHeader = errors.New("invalid magic header")
-
 func (v *VM) validateMagicHeader() error {
 	magicHeaderLen := len(v.magicHeader)
 
@@ -15,11 +12,11 @@ func (v *VM) validateMagicHeader() error {
 	}
 
 	if magicHeaderLen > len(v.program) {
-		return errInvalidMagicHeader
+		return ErrInvalidMagicHeader
 	}
 
 	if !bytes.Equal(v.magicHeader, v.program[:len(v.magicHeader)]) {
-		return errInvalidMagicHeader
+		return ErrInvalidMagicHeader
 	}
 
 	return nil
@@ -13,10 +13,16 @@ const NumRegisters = 32
 // NumRegistersMask is the bitmask to use when masking a raw register.
 const NumRegistersMask = NumRegisters - 1
 
-// StackSize is the size of the stack in bytes.
+// StackSize is the default number of values that fit on the stack.
+// This can be changed per VM with WithStackSize.
 const StackSize = 1024
 
-// HeapSize is the size of the heap in bytes.
+// CallStackSize is the default maximum depth of nested function calls.
+// This can be changed per VM with WithCallStackSize.
+const CallStackSize = 1024
+
+// HeapSize is the default number of values that fit in the heap.
+// This can be changed per VM with WithHeapSize.
 const HeapSize = 65536
 
 // flags defines the flags of the CPU.
@@ -40,15 +46,67 @@ type VM struct {
 	// The length of the program.
 	programLen register
 	// The stack of the virtual machine.
-	stack [StackSize]int64
+	stack []int64
+	// The number of values that fit on the stack.
+	stackSize uint64
 	// The stack pointer of the virtual machine.
 	sp register
-	// The heap memory of the virtual machine.
-	heap [HeapSize]int64
+	// The call frames of the functions that are currently being executed.
+	callStack []CallFrame
+	// The maximum depth of nested function calls.
+	callStackSize uint64
+	// The pages of the heap memory of the virtual machine.
+	heapPages []*heapPage
+	// Whether each page of the heap may be shared with a cloned VM.
+	heapPagesShared []bool
+	// The number of values that fit in the heap.
+	heapSize uint64
+	// The values to store in the heap before the program starts running.
+	heapImage []DataSegment
+	// The error of the heap image, when it does not fit in the heap.
+	heapImageErr error
 	// The flags register.
 	flags flags
+	// Whether the program has stopped itself with a halt instruction.
+	halted bool
+	// The exit status of the program.
+	exitStatus int64
+	// The total number of instructions that have been executed.
+	instructionCount uint64
 	// The host call handler for calling external functions.
 	hostCallHandler HostCallHandler
+	// Whether fuel metering is enabled.
+	fuelEnabled bool
+	// The amount of fuel that is left.
+	fuel uint64
+	// The amount of fuel the VM starts with.
+	fuelLimit uint64
+	// The fuel cost per opcode.
+	fuelCosts map[Opcode]uint64
+	// The hooks that are called for every instruction.
+	traceHooks []TraceHook
+	// The buffer that backs the written registers of the last step.
+	writtenRegistersBuf [1]register
+	// The addresses of the instructions to pause at.
+	breakpoints map[register]struct{}
+	// The heap addresses to pause at when they are accessed.
+	watchpoints map[register]WatchKind
+	// Whether to skip the breakpoint at the program counter when resuming.
+	skipBreakpoint bool
+	// The watchpoint that the last instruction triggered.
+	watchpointHit Pause
+	// Why the VM paused during the last run.
+	pause Pause
+	// Whether undo information is recorded for every instruction.
+	recording bool
+	// The maximum number of undo records to keep, or 0 to keep all of them.
+	undoLimit uint64
+	// The undo records, as a ring buffer.
+	undoLog []undoRecord
+	// The index of the oldest undo record.
+	undoHead uint64
+	// The number of undo records.
+	undoCount uint64
 }
 
 // HostCallHandler defines a handler for calling external functions.
@@ -62,25 +120,55 @@ type HostCallHandler func(
 // New creates a new VM instance.
 func New(program []byte, options ...Option) *VM {
 	vm := &VM{
-		magicHeader: []byte{},
-		pc:          0,
-		registers:   [NumRegisters]int64{},
-		program:     program,
-		programLen:  register(len(program)),
-		stack:       [StackSize]int64{},
-		sp:          0,
-		heap:        [HeapSize]int64{},
+		magicHeader:     []byte{},
+		pc:              0,
+		registers:       [NumRegisters]int64{},
+		program:         program,
+		programLen:      register(len(program)),
+		stack:           nil,
+		stackSize:       StackSize,
+		sp:              0,
+		callStack:       nil,
+		callStackSize:   CallStackSize,
+		heapPages:       nil,
+		heapPagesShared: nil,
+		heapSize:        HeapSize,
+		heapImage:       nil,
+		heapImageErr:    nil,
 		flags: flags{
 			isZero:     false,
 			isNegative: false,
 		},
-		hostCallHandler: nil,
+		halted:              false,
+		exitStatus:          0,
+		instructionCount:    0,
+		hostCallHandler:     nil,
+		fuelEnabled:         false,
+		fuel:                0,
+		fuelLimit:           0,
+		fuelCosts:           nil,
+		traceHooks:          nil,
+		writtenRegistersBuf: [1]register{},
+		breakpoints:         nil,
+		watchpoints:         nil,
+		skipBreakpoint:      false,
+		watchpointHit:       noPause(),
+		pause:               noPause(),
+		recording:           false,
+		undoLimit:           0,
+		undoLog:             nil,
+		undoHead:            0,
+		undoCount:           0,
 	}
 
 	for _, option := range options {
 		option(vm)
 	}
 
+	vm.stack = make([]int64, vm.stackSize)
+	vm.allocateHeap()
+	vm.loadHeapImage()
+
 	return vm
 }
 
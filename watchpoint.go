@@ -0,0 +1,77 @@
+package vm
+
+import (
+	"maps"
+	"slices"
+)
+
+// WatchKind defines which kinds of heap access a watchpoint triggers on.
+type WatchKind byte
+
+const (
+	// WatchRead triggers a watchpoint when OpcodeLoadMemory reads the address.
+	WatchRead WatchKind = 1 << iota
+	// WatchWrite triggers a watchpoint when OpcodeStoreMemory writes the address.
+	WatchWrite
+
+	// WatchReadWrite triggers a watchpoint on both reads and writes.
+	WatchReadWrite = WatchRead | WatchWrite
+)
+
+// Watchpoint defines a heap address that is being watched.
+type Watchpoint struct {
+	// The heap address that is being watched.
+	Address register
+	// The kinds of access that trigger the watchpoint.
+	Kind WatchKind
+}
+
+// SetWatchpoint makes Run and RunContext pause after an instruction accesses
+// the heap address addr, in one of the ways described by kind.
+// Setting a watchpoint on an address that is already being watched
+// replaces its kind.
+func (v *VM) SetWatchpoint(addr register, kind WatchKind) error {
+	if addr >= v.heapSize {
+		return ErrOutOfBounds
+	}
+
+	if v.watchpoints == nil {
+		v.watchpoints = map[register]WatchKind{}
+	}
+
+	v.watchpoints[addr] = kind
+
+	return nil
+}
+
+// ClearWatchpoint removes the watchpoint at addr, if there is one.
+func (v *VM) ClearWatchpoint(addr register) {
+	delete(v.watchpoints, addr)
+}
+
+// Watchpoints returns all watchpoints, sorted by address.
+func (v *VM) Watchpoints() []Watchpoint {
+	addrs := slices.Sorted(maps.Keys(v.watchpoints))
+	watchpoints := make([]Watchpoint, len(addrs))
+
+	for i, addr := range addrs {
+		watchpoints[i] = Watchpoint{Address: addr, Kind: v.watchpoints[addr]}
+	}
+
+	return watchpoints
+}
+
+// checkWatchpoint records a watchpoint hit when the heap address addr is
+// being watched for the access, so the VM pauses after the instruction.
+func (v *VM) checkWatchpoint(instructionStart register, addr register, access WatchKind) {
+	if len(v.watchpoints) == 0 || v.watchpoints[addr]&access == 0 {
+		return
+	}
+
+	v.watchpointHit = Pause{
+		Reason:  PauseReasonWatchpoint,
+		PC:      instructionStart,
+		Address: addr,
+		Access:  access,
+	}
+}
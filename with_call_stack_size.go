@@ -0,0 +1,8 @@
+package vm
+
+// WithCallStackSize sets the maximum depth of nested function calls.
+func WithCallStackSize(size uint64) Option {
+	return func(v *VM) {
+		v.callStackSize = size
+	}
+}
@@ -0,0 +1,18 @@
+package vm
+
+// DefaultFuelCost is the fuel cost of an opcode that is not in the cost table.
+const DefaultFuelCost = 1
+
+// WithFuel limits the amount of fuel that the VM can consume.
+//
+// Before an instruction executes, its cost is taken from the costs table.
+// Opcodes that are not in the table cost DefaultFuelCost.
+// When there is not enough fuel left, execution stops with ErrOutOfFuel.
+func WithFuel(limit uint64, costs map[Opcode]uint64) Option {
+	return func(v *VM) {
+		v.fuelEnabled = true
+		v.fuel = limit
+		v.fuelLimit = limit
+		v.fuelCosts = costs
+	}
+}
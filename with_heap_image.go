@@ -0,0 +1,54 @@
+package vm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// WithHeapImage stores values in the heap, starting at base,
+// before the program starts running.
+// The values are stored again when the VM is Reset without keeping the heap.
+//
+// The option can be used multiple times,
+// and later values overwrite earlier ones at the same address.
+// When the values do not fit in the heap, Run and Step return ErrOutOfBounds.
+func WithHeapImage(base uint64, values []int64) Option {
+	return func(v *VM) {
+		v.heapImage = append(v.heapImage, DataSegment{Address: base, Values: slices.Clone(values)})
+	}
+}
+
+// loadHeapImage stores the values of the heap image in the heap.
+// Values that do not fit in the heap are left out,
+// and the error that Run and Step return is stored.
+func (v *VM) loadHeapImage() {
+	v.heapImageErr = v.validateHeapImage()
+
+	for _, segment := range v.heapImage {
+		for i, val := range segment.Values {
+			addr := segment.Address + uint64(i)
+
+			if addr < segment.Address || addr >= v.heapSize {
+				break
+			}
+
+			v.storeHeap(addr, val)
+		}
+	}
+}
+
+func (v *VM) validateHeapImage() error {
+	for _, segment := range v.heapImage {
+		if segment.Address > v.heapSize || uint64(len(segment.Values)) > v.heapSize-segment.Address {
+			return fmt.Errorf(
+				"%w: heap image of %d values at address %d does not fit in %d values",
+				ErrOutOfBounds,
+				len(segment.Values),
+				segment.Address,
+				v.heapSize,
+			)
+		}
+	}
+
+	return nil
+}
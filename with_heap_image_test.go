@@ -0,0 +1,88 @@
+package vm
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWithHeapImage(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 11,
+		byte(OpcodeLoadMemory), 1, 0,
+		byte(OpcodeStoreMemory), 0, 0,
+		byte(OpcodeHaltRegister), 1,
+	}
+
+	values := []int64{7, 8, 9}
+	vm := New(program, WithHeapSize(16), WithHeapImage(10, values), WithHeapImage(12, []int64{-1}))
+	values[1] = 0
+
+	heap, _ := vm.ReadHeap(10, 3)
+
+	if !slices.Equal(heap, []int64{7, 8, -1}) {
+		t.Fatalf("expected the heap to be [7 8 -1], got %v", heap)
+	}
+
+	result, err := vm.Run()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if heap, _ = vm.ReadHeap(10, 3); result.ExitStatus != 8 || heap[1] != 11 {
+		t.Fatalf("expected exit status 8 and the heap to be written, got %d and %v", result.ExitStatus, heap)
+	}
+
+	vm.Reset(true)
+
+	if result, _ = vm.Run(); result.ExitStatus != 11 {
+		t.Fatalf("expected the heap to be kept, got exit status %d", result.ExitStatus)
+	}
+
+	vm.Reset(false)
+
+	if result, _ = vm.Run(); result.ExitStatus != 8 {
+		t.Fatalf("expected the heap image to be stored again, got exit status %d", result.ExitStatus)
+	}
+}
+
+func TestWithHeapImageErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		base  uint64
+		count int
+	}{
+		{name: "past the end", base: 14, count: 3},
+		{name: "base out of bounds", base: 17, count: 0},
+		{name: "overflow", base: 1<<64 - 1, count: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			vm := New(
+				[]byte{byte(OpcodeNop)},
+				WithHeapSize(16),
+				WithHeapImage(test.base, make([]int64, test.count)),
+			)
+
+			if _, err := vm.Run(); !errors.Is(err, ErrOutOfBounds) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfBounds, err)
+			}
+
+			if _, err := vm.Step(); !errors.Is(err, ErrOutOfBounds) {
+				t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfBounds, err)
+			}
+
+			if vm.PC() != 0 {
+				t.Fatalf("expected no instruction to be executed, got pc %d", vm.PC())
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package vm
+
+// WithHeapSize sets the number of values that fit in the heap.
+func WithHeapSize(size uint64) Option {
+	return func(v *VM) {
+		v.heapSize = size
+	}
+}
@@ -0,0 +1,13 @@
+package vm
+
+// WithRecording makes the VM record undo information for every executed
+// instruction, so execution can be reversed with StepBack and RunBackTo.
+//
+// At most limit instructions are kept, after which the oldest ones are
+// dropped. A limit of 0 keeps every instruction.
+func WithRecording(limit uint64) Option {
+	return func(v *VM) {
+		v.recording = true
+		v.undoLimit = limit
+	}
+}
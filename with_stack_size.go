@@ -0,0 +1,8 @@
+package vm
+
+// WithStackSize sets the number of values that fit on the stack.
+func WithStackSize(size uint64) Option {
+	return func(v *VM) {
+		v.stackSize = size
+	}
+}
@@ -0,0 +1,140 @@
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// traceRecord is a single line of the trace that is written by a traceWriter.
+type traceRecord struct {
+	Step      uint64                `json:"step"`
+	PC        uint64                `json:"pc"`
+	Opcode    string                `json:"opcode"`
+	Operands  []int64               `json:"operands"`
+	Registers []traceRegisterChange `json:"registers,omitempty"`
+	Flags     *traceFlagsChange     `json:"flags,omitempty"`
+	Heap      []traceHeapWrite      `json:"heap,omitempty"`
+	SP        uint64                `json:"sp"`
+	Error     string                `json:"error,omitempty"`
+}
+
+type traceRegisterChange struct {
+	Register int   `json:"register"`
+	Old      int64 `json:"old"`
+	New      int64 `json:"new"`
+}
+
+type traceFlags struct {
+	IsZero     bool `json:"zero"`
+	IsNegative bool `json:"negative"`
+}
+
+type traceFlagsChange struct {
+	Old traceFlags `json:"old"`
+	New traceFlags `json:"new"`
+}
+
+type traceHeapWrite struct {
+	Address int64 `json:"address"`
+	Value   int64 `json:"value"`
+}
+
+// traceWriter writes a JSON object for every executed instruction.
+type traceWriter struct {
+	encoder   *json.Encoder
+	registers [NumRegisters]int64
+	flags     Flags
+	heapWrite *traceHeapWrite
+}
+
+// WithTraceWriter writes a trace of the execution to w, in the JSON Lines
+// format. Every executed instruction results in a single JSON object,
+// with the step number, program counter, opcode, operands,
+// changed registers, changed flags, heap writes and stack pointer.
+// An instruction that runs out of fuel is not written,
+// since it is rolled back and written when it is retried.
+//
+// Every instruction is written separately,
+// so consider wrapping w in a bufio.Writer.
+// When writing fails, execution stops with the write error.
+func WithTraceWriter(w io.Writer) Option {
+	tw := &traceWriter{
+		encoder:   json.NewEncoder(w),
+		registers: [NumRegisters]int64{},
+		flags: Flags{
+			IsZero:     false,
+			IsNegative: false,
+		},
+		heapWrite: nil,
+	}
+
+	return WithTraceHook(TraceHook{
+		Before: tw.before,
+		After:  tw.after,
+	})
+}
+
+func (tw *traceWriter) before(event *TraceEvent) error {
+	tw.registers = event.Registers
+	tw.flags = event.Flags
+	tw.heapWrite = nil
+
+	if event.Opcode == OpcodeStoreMemory && event.Operands != nil {
+		tw.heapWrite = &traceHeapWrite{
+			Address: event.Registers[event.Operands[1].Value],
+			Value:   event.Registers[event.Operands[0].Value],
+		}
+	}
+
+	return nil
+}
+
+func (tw *traceWriter) after(event *TraceEvent) error {
+	record := traceRecord{
+		Step:      event.Step,
+		PC:        event.PC,
+		Opcode:    event.Opcode.String(),
+		Operands:  make([]int64, len(event.Operands)),
+		Registers: nil,
+		Flags:     nil,
+		Heap:      nil,
+		SP:        event.SP,
+		Error:     "",
+	}
+
+	for i, operand := range event.Operands {
+		record.Operands[i] = operand.Value
+	}
+
+	for i, val := range event.Registers {
+		if val != tw.registers[i] {
+			record.Registers = append(record.Registers, traceRegisterChange{
+				Register: i,
+				Old:      tw.registers[i],
+				New:      val,
+			})
+		}
+	}
+
+	if event.Flags != tw.flags {
+		record.Flags = &traceFlagsChange{
+			Old: traceFlags(tw.flags),
+			New: traceFlags(event.Flags),
+		}
+	}
+
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	} else if tw.heapWrite != nil {
+		record.Heap = []traceHeapWrite{*tw.heapWrite}
+	}
+
+	err := tw.encoder.Encode(record)
+
+	if err != nil {
+		return fmt.Errorf("could not write trace: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWithTraceWriter(t *testing.T) {
+	t.Parallel()
+
+	program := []byte{
+		byte(OpcodeLoadImmediate), 0, 0, 0, 0, 0, 0, 0, 0, 5,
+		byte(OpcodeSub), 1, 1, 0,
+		byte(OpcodeStoreMemory), 1, 0,
+		byte(OpcodePush), 0,
+		byte(OpcodeDiv), 2, 0, 3,
+	}
+
+	var buf bytes.Buffer
+
+	_, err := New(program, WithTraceWriter(&buf)).Run()
+
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrDivisionByZero, err)
+	}
+
+	expected := []string{
+		`{"step":0,"pc":0,"opcode":"LoadImmediate","operands":[0,5],"registers":[{"register":0,"old":0,"new":5}],"sp":0}`,
+		`{"step":1,"pc":10,"opcode":"Sub","operands":[1,1,0],"registers":[{"register":1,"old":0,"new":-5}],"flags":{"old":{"zero":false,"negative":false},"new":{"zero":false,"negative":true}},"sp":0}`,
+		`{"step":2,"pc":14,"opcode":"StoreMemory","operands":[1,0],"heap":[{"address":5,"value":-5}],"sp":0}`,
+		`{"step":3,"pc":17,"opcode":"Push","operands":[0],"sp":1}`,
+		`{"step":4,"pc":19,"opcode":"Div","operands":[2,0,3],"sp":1,"error":"division by zero at pc 19 (Div), register r3"}`,
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(expected), len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Fatalf("expected line %d to be:\n%s\ngot:\n%s", i, expected[i], line)
+		}
+	}
+}
+
+func TestWithTraceWriterOutOfFuel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	program := []byte{byte(OpcodeNop), byte(OpcodeNop), byte(OpcodeNop)}
+	vm := New(program, WithFuel(2, nil), WithTraceWriter(&buf))
+
+	if _, err := vm.Run(); !errors.Is(err, ErrOutOfFuel) {
+		t.Fatalf("expected error to be \"%v\", got \"%v\"", ErrOutOfFuel, err)
+	}
+
+	vm.AddFuel(1)
+
+	if _, err := vm.Run(); err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != len(program) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(program), len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		if !strings.HasPrefix(line, fmt.Sprintf(`{"step":%d,`, i)) || strings.Contains(line, "error") {
+			t.Fatalf("expected line %d to be step %d without an error, got %s", i, i, line)
+		}
+	}
+}
+
+func TestWithTraceWriterErr(t *testing.T) {
+	t.Parallel()
+
+	vm := New([]byte{byte(OpcodeNop), byte(OpcodeNop)}, WithTraceWriter(failingWriter{}))
+	_, err := vm.Run()
+
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if vm.PC() != 1 {
+		t.Fatalf("expected execution to stop after the first instruction, got pc %d", vm.PC())
+	}
+}